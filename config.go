@@ -1,11 +1,9 @@
 package almiconfig
 
 import (
+	"context"
 	"reflect"
-	"regexp"
-
-	"github.com/FabianAlmos/almiconfig/consts"
-	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"time"
 )
 
 const (
@@ -14,13 +12,38 @@ const (
 	required  = "^(required)$"
 	envEq     = "(env=)"
 	env       = "^(env=.+)$"
+	envAlias  = "|"
 	typeEq    = "(type=)"
 	_type     = "^(type=.+)$"
 	sliceSep  = "\\[.{1}\\]"
-	slice     = "\\[\\]"
-	typeSlice = "^(type=\\[.{1}\\].+)$"
+	typeSlice = "^(type=\\[.{1}\\].*)$"
 	defaultEq = "(default=)"
 	_default  = "^(default=.+)$"
+	prefixEq  = "(prefix=)"
+	prefix    = "^(prefix=.+)$"
+	keyEq     = "(key=)"
+	key       = "^(key=.+)$"
+	flagEq    = "(flag=)"
+	_flag     = "^(flag=.+)$"
+	descEq    = "(desc=)"
+	desc      = "^(desc=.+)$"
+	minEq     = "(min=)"
+	_min      = "^(min=.+)$"
+	maxEq     = "(max=)"
+	_max      = "^(max=.+)$"
+	lenEq     = "(len=)"
+	_len      = "^(len=.+)$"
+	minLenEq  = "(minlen=)"
+	minLen    = "^(minlen=.+)$"
+	maxLenEq  = "(maxlen=)"
+	maxLen    = "^(maxlen=.+)$"
+
+	defaultOnEmpty = "^(default_on_empty)$"
+	notEmpty       = "^(notempty)$"
+	expand         = "^(expand)$"
+	file           = "^(file)$"
+	reloadable     = "^(reloadable)$"
+	secret         = "^(secret)$"
 
 	_bool    = "bool"
 	_string  = "string"
@@ -39,55 +62,61 @@ const (
 	_float64 = "float64"
 	_rune    = "rune"
 	_byte    = "byte"
+
+	_duration = "duration"
+	_time     = "time"
+
+	layoutEq = "(layout=)"
+	layout   = "^(layout=.+)$"
+
+	mapSeps    = "\\{.{2}\\}"
+	typeMap    = "^(type=\\{.{2}\\}(map\\[.+\\].+)?)$"
+	mapKeyElem = "^map\\[(.+?)\\](.+)$"
 )
 
+// builtinTypes are the Go types of the type names the built-in conversions support.
+var builtinTypes = map[string]reflect.Type{
+	_string:   reflect.TypeOf(""),
+	_bool:     reflect.TypeOf(false),
+	_int:      reflect.TypeOf(int(0)),
+	_int8:     reflect.TypeOf(int8(0)),
+	_int16:    reflect.TypeOf(int16(0)),
+	_int32:    reflect.TypeOf(int32(0)),
+	_int64:    reflect.TypeOf(int64(0)),
+	_uint:     reflect.TypeOf(uint(0)),
+	_uint8:    reflect.TypeOf(uint8(0)),
+	_uint16:   reflect.TypeOf(uint16(0)),
+	_uint32:   reflect.TypeOf(uint32(0)),
+	_uint64:   reflect.TypeOf(uint64(0)),
+	_uintptr:  reflect.TypeOf(uintptr(0)),
+	_float32:  reflect.TypeOf(float32(0)),
+	_float64:  reflect.TypeOf(float64(0)),
+	_rune:     reflect.TypeOf(rune(0)),
+	_byte:     reflect.TypeOf(byte(0)),
+	_duration: reflect.TypeOf(time.Duration(0)),
+	_time:     reflect.TypeOf(time.Time{}),
+}
+
 func setFieldValue(envVar any, cfg reflect.Value, val *configValue, cc *configConstraint) error {
-	envVarValue := reflect.ValueOf(envVar)
-	field := cfg.FieldByName(val.Field.Name)
-	structTagType := string(regexp.MustCompile(slice).ReplaceAll([]byte(field.Type().String()), []byte(consts.EMPTY)))
+	envVarValue, ok := convertTo(reflect.ValueOf(envVar), cc.FieldType)
+	if !ok {
+		return cc.typeMismatchErr(cfg, val)
+	}
 
-	if !(cc.SliceType && cc.Type == structTagType) &&
-		field.Type().String() != cc.Type &&
-		!(field.Type().String() == _string && cc.Type == consts.EMPTY) {
-		return almierrors.FieldStructTagTypeMismatchErr.Build(
-			val.Field.Name,
-			field.Type().String(),
-			cfg.Type().String(),
-			cc.Type,
-			cfg.Type().String(),
-		)
+	if val.isPointer() {
+		setPointer(val.Value, envVarValue, true)
+		return nil
 	}
 
-	field.Set(envVarValue)
+	val.Value.Set(envVarValue)
 
 	return nil
 }
 
+// ValidateConfig reads the config from the environment, it is a shorthand for Load with an EnvSource.
 func ValidateConfig[T any](config T) (*T, error) {
-	cfg := reflect.ValueOf(&config).Elem()
-	for i := 0; i < cfg.NumField(); i++ {
-		val := getConfigValueByIndex(cfg, i)
-
-		cfgConstraint := newConfigConstraint(val)
-		if err := cfgConstraint.parseConstraints(val.Constraints); err != nil {
-			return nil, err
-		}
-
-		envVar, err := cfgConstraint.findType()
-		if err != nil {
-			if cfgConstraint.HasDefault {
-				return nil, almierrors.FailedToConvertDefaultTypeErr.Build(cfgConstraint.Default, cfgConstraint.EnvName, cfgConstraint.Type)
-			}
-			return nil, almierrors.FailedToConvertTypeErr.Build(cfgConstraint.EnvName, cfgConstraint.Type)
-		}
-
-		if err := setFieldValue(envVar, cfg, val, cfgConstraint); err != nil {
-			return nil, err
-		}
-
-		if err := cfgConstraint.checkConstraints(val); err != nil {
-			return nil, err
-		}
+	if err := Load(context.Background(), &config, EnvSource{}); err != nil {
+		return nil, err
 	}
 
 	return &config, nil
@@ -0,0 +1,143 @@
+package almiconfig
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+const (
+	minBound    = "min"
+	maxBound    = "max"
+	lenBound    = "len"
+	minLenBound = "minlen"
+	maxLenBound = "maxlen"
+)
+
+// bound is a 'min=', 'max=', 'len=', 'minlen=' or 'maxlen=' constraint of a field.
+type bound struct {
+	name  string
+	value string
+}
+
+// length reports whether the bound limits the length of the value instead of the value itself.
+func (b bound) length() bool {
+	return b.name != minBound && b.name != maxBound
+}
+
+// holds reports whether c, the comparison of the value or its length with the bound, satisfies the bound.
+func (b bound) holds(c int) bool {
+	switch b.name {
+	case minBound, minLenBound:
+		return c >= 0
+	case maxBound, maxLenBound:
+		return c <= 0
+	default:
+		return c == 0
+	}
+}
+
+// bounds are the bounds set on the field.
+func (cc *configConstraint) bounds() []bound {
+	var bounds []bound
+	for _, b := range []bound{{minBound, cc.Min}, {maxBound, cc.Max}, {lenBound, cc.Len}, {minLenBound, cc.MinLen}, {maxLenBound, cc.MaxLen}} {
+		if b.value != consts.EMPTY {
+			bounds = append(bounds, b)
+		}
+	}
+
+	return bounds
+}
+
+// checkBounds checks v, the converted value of the field, against its bounds. The bounds of fields that
+// are not set are not enforced, v is the zero Value then and only the bounds themselves are checked.
+func (cc *configConstraint) checkBounds(v reflect.Value) error {
+	t := cc.FieldType
+	if v.IsValid() {
+		t = v.Type()
+	}
+
+	for _, b := range cc.bounds() {
+		if err := cc.checkBound(b, t, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cc *configConstraint) checkBound(b bound, t reflect.Type, v reflect.Value) error {
+	if b.length() {
+		switch t.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		default:
+			return almierrors.BoundTypeErr.Build(cc.FieldName, b.name, t)
+		}
+
+		limit, err := strconv.Atoi(b.value)
+		if err != nil || limit < 0 {
+			return almierrors.BoundInvalidErr.Build(cc.FieldName, b.name, b.value)
+		}
+
+		if !v.IsValid() {
+			return nil
+		}
+
+		n := v.Len()
+		if v.Kind() == reflect.String {
+			n = utf8.RuneCountInString(v.String())
+		}
+
+		if !b.holds(cmp.Compare(n, limit)) {
+			return &almierrors.BoundError{Field: cc.FieldName, Constraint: b.name, Bound: b.value, Actual: strconv.Itoa(n), Length: true}
+		}
+		return nil
+	}
+
+	compare, ok, err := compareBound(t, b.value)
+	switch {
+	case !ok:
+		return almierrors.BoundTypeErr.Build(cc.FieldName, b.name, t)
+	case err != nil:
+		return almierrors.BoundInvalidErr.Wrap(err, cc.FieldName, b.name, b.value)
+	case !v.IsValid():
+		return nil
+	}
+
+	if !b.holds(compare(v)) {
+		return &almierrors.BoundError{Field: cc.FieldName, Constraint: b.name, Bound: b.value, Actual: fmt.Sprint(v.Interface())}
+	}
+
+	return nil
+}
+
+// compareBound parses bound like the values of the number or duration type t and returns a function comparing
+// values of t with it, ok is false when t is not a number.
+func compareBound(t reflect.Type, bound string) (compare func(reflect.Value) int, ok bool, err error) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if t == builtinTypes[_duration] {
+			var d time.Duration
+			d, err = time.ParseDuration(bound)
+			n = int64(d)
+		} else {
+			n, err = strconv.ParseInt(bound, intBase(bound), t.Bits())
+		}
+		return func(v reflect.Value) int { return cmp.Compare(v.Int(), n) }, true, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(bound, intBase(bound), t.Bits())
+		return func(v reflect.Value) int { return cmp.Compare(v.Uint(), n) }, true, err
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(bound, t.Bits())
+		return func(v reflect.Value) int { return cmp.Compare(v.Float(), n) }, true, err
+	default:
+		return nil, false, nil
+	}
+}
@@ -0,0 +1,139 @@
+package almiconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	poolEnv     = "POOL"
+	nameEnv     = "NAME"
+	codeEnv     = "CODE"
+	hostsEnv    = "HOSTS"
+	tagsEnv     = "TAGS"
+	boundPort   = "8080"
+	boundPortV  = int(8080)
+	boundPool   = "10"
+	boundPoolV  = uint(10)
+	boundRatio  = "0.5"
+	boundName   = "héllo"
+	boundCode   = "ab"
+	boundHosts  = "a,b"
+	boundTags   = "a=1"
+	longName    = "a very long name"
+	noHosts     = "a"
+	bigTimeout  = "2m"
+	hexPort     = "0x20"
+	octalPort   = "010"
+	boundErrMsg = "AlmiConfigError: Field: 'Port': value '70000' violates the constraint: 'max=65535' (key: 'PORT')"
+	lenErrMsg   = "AlmiConfigError: Field: 'Name': length 16 violates the constraint: 'maxlen=8' (key: 'NAME')"
+)
+
+type testBoundConfig struct {
+	Port    int            `almi:"env=PORT,min=1,max=65535"`
+	Pool    uint           `almi:"env=POOL,min=1,max=100"`
+	Ratio   float64        `almi:"env=RATIO,min=0,max=1"`
+	Timeout time.Duration  `almi:"env=TIMEOUT,min=1s,max=1m,default=30s"`
+	Name    string         `almi:"env=NAME,minlen=2,maxlen=8"`
+	Code    *string        `almi:"env=CODE,len=2"`
+	Hosts   []string       `almi:"env=HOSTS,type=[,]string,minlen=2"`
+	Tags    map[string]int `almi:"env=TAGS,type={,=},maxlen=1"`
+}
+
+func TestLoad_Successful_Bounds(t *testing.T) {
+	cfg := testBoundConfig{}
+	err := Load(context.Background(), &cfg, MapSource{
+		portEnv: boundPort, poolEnv: boundPool, ratioEnv: boundRatio, nameEnv: boundName,
+		codeEnv: boundCode, hostsEnv: boundHosts, tagsEnv: boundTags,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, boundPortV, cfg.Port)
+	assert.Equal(t, boundPoolV, cfg.Pool)
+	assert.Equal(t, 30*time.Second, cfg.Timeout)
+	assert.Equal(t, boundName, cfg.Name)
+
+	// the bounds of fields that aren't set are not enforced
+	cfg = testBoundConfig{}
+	assert.Nil(t, Load(context.Background(), &cfg, MapSource{}))
+	assert.Empty(t, cfg.Name)
+	assert.Nil(t, cfg.Code)
+}
+
+func TestLoad_Successful_PrefixedBounds(t *testing.T) {
+	type prefixedBoundConfig struct {
+		Port int  `almi:"env=PORT,min=0x10,max=0o777"`
+		Pool uint `almi:"env=POOL,min=0b1,max=0x_ff"`
+	}
+
+	cfg := prefixedBoundConfig{}
+	err := Load(context.Background(), &cfg, MapSource{portEnv: hexPort, poolEnv: boundPool})
+	assert.Nil(t, err)
+	assert.Equal(t, 32, cfg.Port)
+
+	// leading zeros are decimal in bounds like in values, so 010 is 10 and not 8
+	err = Load(context.Background(), &cfg, MapSource{portEnv: octalPort})
+	assert.ErrorIs(t, err, almierrors.ValueBoundErr)
+}
+
+func TestLoad_Fail_Bounds(t *testing.T) {
+	cfg := testBoundConfig{}
+	err := Load(context.Background(), &cfg, MapSource{
+		portEnv: tooBigPort, nameEnv: longName, hostsEnv: noHosts, timeoutEnv: bigTimeout,
+	})
+
+	var errs almierrors.Errors
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 4)
+	assert.ErrorIs(t, err, almierrors.ErrBound)
+	assert.Equal(t, boundErrMsg, errs[0].Error())
+	assert.Equal(t, lenErrMsg, errs[2].Error())
+
+	var boundErr *almierrors.BoundError
+	assert.True(t, errors.As(errs[0], &boundErr))
+	assert.Equal(t, maxBound, boundErr.Constraint)
+	assert.Equal(t, "65535", boundErr.Bound)
+	assert.Equal(t, tooBigPort, boundErr.Actual)
+	assert.ErrorIs(t, errs[0], almierrors.ValueBoundErr)
+
+	assert.True(t, errors.As(errs[3], &boundErr))
+	assert.Equal(t, minLenBound, boundErr.Constraint)
+	assert.Equal(t, "1", boundErr.Actual)
+	assert.True(t, boundErr.Length)
+	assert.ErrorIs(t, errs[3], almierrors.LengthBoundErr)
+
+	assert.True(t, errors.As(errs[1], &boundErr))
+	assert.Equal(t, "2m0s", boundErr.Actual)
+}
+
+func TestLoad_Fail_BoundConstraints(t *testing.T) {
+	err := Load(context.Background(), &struct {
+		Port int `almi:"env=PORT,min=one"`
+	}{}, MapSource{})
+	assert.ErrorIs(t, err, almierrors.BoundInvalidErr)
+	assert.ErrorIs(t, err, almierrors.ErrInvalidTag)
+
+	err = Load(context.Background(), &struct {
+		Port int `almi:"env=PORT,maxlen=4"`
+	}{}, MapSource{})
+	assert.ErrorIs(t, err, almierrors.BoundTypeErr)
+
+	err = Load(context.Background(), &struct {
+		Name *string `almi:"env=NAME,max=4"`
+	}{}, MapSource{})
+	assert.ErrorIs(t, err, almierrors.BoundTypeErr)
+
+	err = Load(context.Background(), &struct {
+		Hosts []string `almi:"env=HOSTS,type=[,]string,len=-1"`
+	}{}, MapSource{})
+	assert.ErrorIs(t, err, almierrors.BoundInvalidErr)
+
+	err = Load(context.Background(), &struct {
+		Postgres testDiffDBConfig `almi:"min=1"`
+	}{}, MapSource{})
+	assert.ErrorIs(t, err, almierrors.StructConstraintErr)
+}
@@ -1,7 +1,10 @@
 package almiconfig
 
 import (
+	"reflect"
 	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/FabianAlmos/almiconfig/consts"
 	almierrors "github.com/FabianAlmos/almiconfig/errors"
@@ -9,21 +12,78 @@ import (
 
 type configConstraint struct {
 	FieldName string
+	FieldType reflect.Type
 
 	Required bool
 	EnvName  string
 	Type     string
 
+	// Aliases are the legacy names of the field, tried in order when EnvName is not present in Source.
+	// When one of them matches it becomes the EnvName and Preferred keeps the name that should be used instead.
+	Aliases   []string
+	Preferred string
+
 	SliceType bool
 	Separator string
 
-	HasDefault bool
-	Default    string
+	MapType     bool
+	KeyType     string
+	KVSeparator string
+
+	HasDefault     bool
+	Default        string
+	DefaultOnEmpty bool
+
+	NotEmpty bool
+
+	// Min and Max bound the value of numbers and durations, Len, MinLen and MaxLen the length of strings,
+	// in runes, and the number of elements of slices and maps.
+	Min    string
+	Max    string
+	Len    string
+	MinLen string
+	MaxLen string
+
+	// Expand expands the ${VAR} references of the value, or of the default, once it has been looked up.
+	Expand bool
+
+	// File reads the value from the file at the path the variable is set to, FileSizeLimit caps the size of the file.
+	File          bool
+	FileSizeLimit int64
+
+	Layout string
+
+	// Decode is set when the field is decoded through a custom decoder instead of the built-in conversions.
+	Decode decodeFunc
+
+	Prefix string
+
+	// Key overrides the name of the field in the document path, Path is the document path the field is looked up with
+	// in the sources that support it.
+	Key  string
+	Path []string
+
+	// Flag overrides the name of the command-line flag of the field, Desc is the usage text of the flag.
+	Flag string
+	Desc string
+
+	// Reloadable marks a field that can be changed while the program runs, Secret a field whose value is never shown.
+	Reloadable bool
+	Secret     bool
+
+	Source Source
+	// Value, Present and DefaultUsed are filled in by checkConstraints once the field has been looked up in Source,
+	// Resolved tells that they are, so the conversions read Value instead of looking the field up again.
+	Value       string
+	Present     bool
+	DefaultUsed bool
+	Resolved    bool
 }
 
 func newConfigConstraint(val *configValue) *configConstraint {
 	return &configConstraint{
-		FieldName: val.Field.Name,
+		FieldName: val.Path,
+		FieldType: val.valueType(),
 	}
 }
 
@@ -34,9 +94,33 @@ func (cc *configConstraint) parseConstraints(constraints []string) error {
 			cc.Required = true
 			continue
 		case regexp.MustCompile(env).MatchString(c):
-			cc.EnvName = string(regexp.MustCompile(envEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			// env=NEW|OLD accepts legacy names, the first name present wins
+			names := strings.Split(string(regexp.MustCompile(envEq).ReplaceAll([]byte(c), []byte(consts.EMPTY))), envAlias)
+			if slices.Contains(names, consts.EMPTY) {
+				return almierrors.EnvAliasEmptyErr.Build(cc.FieldName, c)
+			}
+			cc.EnvName = names[0]
+			cc.Aliases = names[1:]
 			continue
 		case regexp.MustCompile(_type).MatchString(c):
+			if regexp.MustCompile(typeMap).MatchString(c) {
+				mapType := regexp.MustCompile(typeEq).ReplaceAll([]byte(c), []byte(consts.EMPTY))
+				seps := regexp.MustCompile(mapSeps).Find(mapType)
+				if len(seps) != 4 || seps[1] == seps[2] {
+					return almierrors.MapSepUndefErr.Build(cc.FieldName)
+				}
+				cc.MapType = true
+				cc.Separator = string(seps[1])
+				cc.KVSeparator = string(seps[2])
+
+				// the key and value types are inferred from the field when only the separators are set
+				keyElem := regexp.MustCompile(mapKeyElem).FindSubmatch(regexp.MustCompile(mapSeps).ReplaceAll(mapType, []byte(consts.EMPTY)))
+				if keyElem != nil {
+					cc.KeyType = string(keyElem[1])
+					cc.Type = string(keyElem[2])
+				}
+				continue
+			}
 			if regexp.MustCompile(typeSlice).MatchString(c) {
 				sliceType := regexp.MustCompile(typeEq).ReplaceAll([]byte(c), []byte(consts.EMPTY))
 				sep := regexp.MustCompile(sliceSep).Find(sliceType)
@@ -55,6 +139,54 @@ func (cc *configConstraint) parseConstraints(constraints []string) error {
 			cc.HasDefault = true
 			cc.Default = string(regexp.MustCompile(defaultEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
 			continue
+		case regexp.MustCompile(defaultOnEmpty).MatchString(c):
+			cc.DefaultOnEmpty = true
+			continue
+		case regexp.MustCompile(layout).MatchString(c):
+			cc.Layout = string(regexp.MustCompile(layoutEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
+		case regexp.MustCompile(notEmpty).MatchString(c):
+			cc.NotEmpty = true
+			continue
+		case regexp.MustCompile(expand).MatchString(c):
+			cc.Expand = true
+			continue
+		case regexp.MustCompile(file).MatchString(c):
+			cc.File = true
+			continue
+		case regexp.MustCompile(_min).MatchString(c):
+			cc.Min = string(regexp.MustCompile(minEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
+		case regexp.MustCompile(_max).MatchString(c):
+			cc.Max = string(regexp.MustCompile(maxEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
+		case regexp.MustCompile(_len).MatchString(c):
+			cc.Len = string(regexp.MustCompile(lenEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
+		case regexp.MustCompile(minLen).MatchString(c):
+			cc.MinLen = string(regexp.MustCompile(minLenEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
+		case regexp.MustCompile(maxLen).MatchString(c):
+			cc.MaxLen = string(regexp.MustCompile(maxLenEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
+		case regexp.MustCompile(reloadable).MatchString(c):
+			cc.Reloadable = true
+			continue
+		case regexp.MustCompile(secret).MatchString(c):
+			cc.Secret = true
+			continue
+		case regexp.MustCompile(prefix).MatchString(c):
+			cc.Prefix = string(regexp.MustCompile(prefixEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
+		case regexp.MustCompile(key).MatchString(c):
+			cc.Key = string(regexp.MustCompile(keyEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
+		case regexp.MustCompile(_flag).MatchString(c):
+			cc.Flag = string(regexp.MustCompile(flagEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
+		case regexp.MustCompile(desc).MatchString(c):
+			cc.Desc = string(regexp.MustCompile(descEq).ReplaceAll([]byte(c), []byte(consts.EMPTY)))
+			continue
 		default:
 			return almierrors.ConstraintUnknownErr.Build(c, cc.FieldName)
 		}
@@ -69,6 +201,14 @@ func (cc *configConstraint) findType() (any, error) {
 		err    error
 	)
 
+	if cc.Decode != nil {
+		return decodeCustom(*cc)
+	}
+
+	if cc.MapType {
+		return atoMap(*cc)
+	}
+
 	switch cc.Type {
 	case consts.EMPTY, _string:
 		envVar, err = str[string](*cc)
@@ -104,6 +244,10 @@ func (cc *configConstraint) findType() (any, error) {
 		envVar, err = atoRB[byte](*cc)
 	case _rune:
 		envVar, err = atoRB[rune](*cc)
+	case _duration:
+		envVar, err = atoDuration(*cc)
+	case _time:
+		envVar, err = atoTime(*cc)
 	default:
 		return nil, almierrors.UnrecognizedTypeErr.Build(cc.Type)
 	}
@@ -111,14 +255,211 @@ func (cc *configConstraint) findType() (any, error) {
 	return envVar, err
 }
 
+// checkConstraints looks the field up in its source and checks the constraints that depend on whether it was present,
+// so a missing required field is reported the same way whatever its type is.
 func (cc *configConstraint) checkConstraints(val *configValue) error {
-	if cc.EnvName == consts.EMPTY {
-		return almierrors.EnvConstraintUndefErr.Build(val.Field.Name)
+	// fields without an env name can still be read from sources that look them up by their document path
+	if cc.EnvName == consts.EMPTY && !cc.sources().hasPathSource() {
+		return almierrors.EnvConstraintUndefErr.Build(val.Path)
+	}
+
+	cc.resolveAlias()
+
+	envVal, present, err := cc.lookup()
+	if err != nil {
+		return err
+	}
+	fromFile := cc.File
+	if !present && cc.EnvName != consts.EMPTY {
+		// KEY_FILE=/run/secrets/key reads the value of KEY from a file
+		if path, ok := cc.source().Lookup(cc.EnvName + fileSuffix); ok {
+			cc.EnvName += fileSuffix
+			envVal, present, fromFile = path, true, true
+		}
 	}
 
-	if cc.Required && val.Value.String() == consts.EMPTY {
-		return almierrors.FieldRequiredErr.Build(val.Field.Name)
+	if cc.Expand && present {
+		expanded, err := cc.expand(envVal)
+		if err != nil {
+			return err
+		}
+		envVal = expanded
+	}
+
+	if fromFile && present {
+		contents, err := cc.readFile(envVal)
+		if err != nil {
+			return err
+		}
+		envVal = contents
+	}
+
+	cc.Resolved = true
+	cc.Value = envVal
+	cc.Present = present
+	cc.DefaultUsed = cc.usesDefault(envVal, present)
+	if cc.DefaultUsed {
+		cc.Value = cc.Default
+		if cc.Expand {
+			expanded, err := cc.expand(cc.Default)
+			if err != nil {
+				return err
+			}
+			cc.Value = expanded
+		}
+	}
+
+	if cc.Required && !present && !cc.DefaultUsed {
+		return almierrors.FieldRequiredErr.Build(val.Path)
+	}
+
+	if cc.NotEmpty && present && !cc.DefaultUsed && strings.TrimSpace(envVal) == consts.EMPTY {
+		return almierrors.FieldEmptyErr.Build(val.Path, cc.key())
 	}
 
 	return nil
 }
+
+// checkStructConstraints makes sure a nested struct field only carries constraints that apply to structs.
+func (cc *configConstraint) checkStructConstraints(val *configValue) error {
+	if cc.EnvName != consts.EMPTY || cc.Type != consts.EMPTY || cc.HasDefault || cc.DefaultOnEmpty || cc.NotEmpty || cc.Expand || cc.File || cc.Layout != consts.EMPTY ||
+		cc.Flag != consts.EMPTY || cc.Desc != consts.EMPTY || cc.Reloadable || cc.Secret || len(cc.bounds()) != 0 {
+		return almierrors.StructConstraintErr.Build(val.Path)
+	}
+
+	return nil
+}
+
+// resolveAlias switches EnvName to the first of its legacy aliases present in the source when EnvName itself is not.
+func (cc *configConstraint) resolveAlias() {
+	if _, ok, err := cc.lookup(); ok || err != nil {
+		return
+	}
+
+	for _, alias := range cc.Aliases {
+		if _, ok := cc.source().Lookup(alias); ok {
+			cc.Preferred = cc.EnvName
+			cc.EnvName = alias
+			return
+		}
+	}
+}
+
+// withPrefix prepends prefix to the env name and all of its aliases.
+func (cc *configConstraint) withPrefix(prefix string) {
+	cc.EnvName = prefix + cc.EnvName
+	for i, alias := range cc.Aliases {
+		cc.Aliases[i] = prefix + alias
+	}
+}
+
+// sources are the sources of the field in order of precedence.
+func (cc *configConstraint) sources() sourceChain {
+	if chain, ok := cc.source().(sourceChain); ok {
+		return chain
+	}
+
+	return sourceChain{cc.source()}
+}
+
+// match is a value of the field found in one of its sources.
+type match struct {
+	val string
+	// key is the key the value was found with, source the name of the source it was found in.
+	key    string
+	source string
+}
+
+// find looks the field up in its sources in order, by its document path in the sources that support it
+// and by its env name in all of them. Document values are flattened with the separators of the field,
+// an error is returned when their elements can't be told apart once joined.
+func (cc *configConstraint) find() (match, bool, error) {
+	for _, src := range cc.sources() {
+		if ps, ok := asPathSource(src); ok {
+			if m, ok, err := cc.findPath(ps, src, cc.Path, strings.Join(cc.Path, pathSep)); ok || err != nil {
+				return m, ok, err
+			}
+
+			if cc.EnvName != consts.EMPTY {
+				if m, ok, err := cc.findPath(ps, src, strings.Split(cc.EnvName, pathSep), cc.EnvName); ok || err != nil {
+					return m, ok, err
+				}
+			}
+		}
+
+		if cc.EnvName == consts.EMPTY {
+			continue
+		}
+
+		if val, ok := src.Lookup(cc.EnvName); ok {
+			return match{val: val, key: cc.EnvName, source: sourceName(src)}, true, nil
+		}
+	}
+
+	return match{}, false, nil
+}
+
+// findPath looks path up in ps, the document source src, key is the key the value is reported to be found with.
+func (cc *configConstraint) findPath(ps PathSource, src Source, path []string, key string) (match, bool, error) {
+	val, ok := ps.LookupPath(path)
+	if !ok {
+		return match{}, false, nil
+	}
+
+	flat, err := flatten(val, cc.separator(), cc.kvSeparator())
+	if err != nil {
+		return match{}, false, err
+	}
+
+	return match{val: flat, key: key, source: sourceName(src)}, true, nil
+}
+
+func (cc *configConstraint) separator() string {
+	if cc.Separator == consts.EMPTY {
+		return defaultSeparator
+	}
+	return cc.Separator
+}
+
+func (cc *configConstraint) kvSeparator() string {
+	if cc.KVSeparator == consts.EMPTY {
+		return defaultKVSeparator
+	}
+	return cc.KVSeparator
+}
+
+func (cc *configConstraint) source() Source {
+	if cc.Source == nil {
+		return EnvSource{}
+	}
+
+	return cc.Source
+}
+
+// lookup reads the raw value of the field from its source, the environment is used when no source is set.
+func (cc *configConstraint) lookup() (string, bool, error) {
+	m, ok, err := cc.find()
+	return m.val, ok, err
+}
+
+// key is the key the field is looked up with, as shown in errors: its env name, or its document path
+// for fields that are only read from documents.
+func (cc *configConstraint) key() string {
+	if cc.EnvName == consts.EMPTY {
+		return strings.Join(cc.Path, pathSep)
+	}
+	return cc.EnvName
+}
+
+// typeName is the name of the type the field is converted to, as shown in errors.
+func (cc *configConstraint) typeName() string {
+	if cc.Type == consts.EMPTY && cc.Decode != nil {
+		return cc.FieldType.String()
+	}
+
+	if t, ok := cc.tagType(); ok && cc.MapType {
+		return t.String()
+	}
+
+	return cc.Type
+}
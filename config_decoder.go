@@ -0,0 +1,116 @@
+package almiconfig
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+// Decoder is implemented by types that decode themselves from a raw config value.
+// Like encoding.TextUnmarshaler, it must be implemented on the pointer receiver.
+type Decoder interface {
+	Decode(value string) error
+}
+
+type decodeFunc func(value string) (reflect.Value, error)
+
+var (
+	decoderType         = reflect.TypeOf((*Decoder)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	decodersMu sync.RWMutex
+	decoders   = map[reflect.Type]decodeFunc{}
+)
+
+// RegisterDecoder registers fn as the decoder of every config field of type T, and of the elements of []T fields.
+// Registered decoders take precedence over Decoder and encoding.TextUnmarshaler implementations,
+// registering a decoder for the same type again replaces the previous one.
+func RegisterDecoder[T any](fn func(value string) (T, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+
+	decoders[t] = func(value string) (reflect.Value, error) {
+		v, err := fn(value)
+		return reflect.ValueOf(&v).Elem(), err
+	}
+}
+
+// decoderFor returns the function decoding a single value of type t, or nil when t has no custom decoder.
+func decoderFor(t reflect.Type) decodeFunc {
+	decodersMu.RLock()
+	decode, ok := decoders[t]
+	decodersMu.RUnlock()
+	if ok {
+		return decode
+	}
+
+	ptrType := reflect.PointerTo(t)
+	switch {
+	case ptrType.Implements(decoderType):
+		return func(value string) (reflect.Value, error) {
+			ptr := reflect.New(t)
+			err := ptr.Interface().(Decoder).Decode(value)
+			return ptr.Elem(), err
+		}
+	case ptrType.Implements(textUnmarshalerType):
+		return func(value string) (reflect.Value, error) {
+			ptr := reflect.New(t)
+			err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+			return ptr.Elem(), err
+		}
+	}
+
+	return nil
+}
+
+// decoderFor returns the custom decoder of t for the field, time.Time fields with a 'layout=' use the built-in conversion.
+func (cc *configConstraint) decoderFor(t reflect.Type) decodeFunc {
+	if t == builtinTypes[_time] && cc.Layout != consts.EMPTY {
+		return nil
+	}
+
+	return decoderFor(t)
+}
+
+func decodeCustom(cc configConstraint) (val any, err error) {
+	zero := reflect.Zero(cc.FieldType).Interface()
+
+	envVal, err := getEnvVal(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cc.Required && envVal == consts.EMPTY {
+		return zero, nil
+	}
+
+	if cc.SliceType && cc.Separator != consts.EMPTY {
+		vals := strings.Split(envVal, cc.Separator)
+		decoded := reflect.MakeSlice(cc.FieldType, 0, len(vals))
+		for _, val := range vals {
+			v, err := cc.Decode(val)
+			if err != nil {
+				return zero, err
+			}
+
+			decoded = reflect.Append(decoded, v)
+		}
+
+		return decoded.Interface(), nil
+	} else if cc.SliceType && cc.Separator == consts.EMPTY {
+		return zero, almierrors.SepDecoderErr.Build()
+	}
+
+	v, err := cc.Decode(envVal)
+	if err != nil {
+		return zero, err
+	}
+
+	return v.Interface(), nil
+}
@@ -0,0 +1,112 @@
+package almiconfig
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	logLevelEnv     = "LOG_LEVEL"
+	regionEnv       = "REGION"
+	regionsEnv      = "REGIONS"
+	listenEnv       = "LISTEN"
+	debugLevel      = "debug"
+	infoLevel       = "INFO"
+	badLevel        = "loud"
+	regionCode      = "eu-west-1"
+	otherRegionCode = "us-east-1"
+	regionCodes     = "eu-west-1;us-east-1"
+	badRegion       = "eu"
+	listenAddr      = "127.0.0.1"
+	regionDashes    = 2
+)
+
+var errBadLevel = errors.New("unknown log level")
+
+type testLogLevel int
+
+const (
+	testDebugLevel testLogLevel = iota
+	testInfoLevel
+)
+
+func parseTestLogLevel(value string) (testLogLevel, error) {
+	switch strings.ToLower(value) {
+	case debugLevel:
+		return testDebugLevel, nil
+	case strings.ToLower(infoLevel):
+		return testInfoLevel, nil
+	}
+	return 0, errBadLevel
+}
+
+type testRegion struct {
+	Code string
+}
+
+func (r *testRegion) Decode(value string) error {
+	if strings.Count(value, "-") != regionDashes {
+		return almierrors.ErrConversion
+	}
+	r.Code = value
+	return nil
+}
+
+type testConfigDecoders struct {
+	LogLevel testLogLevel `almi:"env=LOG_LEVEL,default=info"`
+	Region   testRegion   `almi:"required,env=REGION"`
+	Regions  []testRegion `almi:"env=REGIONS,type=[;]"`
+	Listen   netip.Addr   `almi:"env=LISTEN"`
+}
+
+func TestRegisterDecoder_Successful(t *testing.T) {
+	RegisterDecoder(parseTestLogLevel)
+
+	cfg := testConfigDecoders{}
+	err := Load(context.Background(), &cfg, MapSource{
+		logLevelEnv: debugLevel,
+		regionEnv:   regionCode,
+		regionsEnv:  regionCodes,
+		listenEnv:   listenAddr,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, testDebugLevel, cfg.LogLevel)
+	assert.Equal(t, testRegion{Code: regionCode}, cfg.Region)
+	assert.Equal(t, []testRegion{{Code: regionCode}, {Code: otherRegionCode}}, cfg.Regions)
+	assert.Equal(t, netip.MustParseAddr(listenAddr), cfg.Listen)
+}
+
+func TestRegisterDecoder_SuccessfulDefault(t *testing.T) {
+	RegisterDecoder(parseTestLogLevel)
+
+	cfg := testConfigDecoders{}
+	err := Load(context.Background(), &cfg, MapSource{regionEnv: regionCode})
+	assert.Nil(t, err)
+	assert.Equal(t, testInfoLevel, cfg.LogLevel)
+	assert.False(t, cfg.Listen.IsValid())
+}
+
+func TestRegisterDecoder_FailWrapsDecoderError(t *testing.T) {
+	RegisterDecoder(parseTestLogLevel)
+
+	cfg := testConfigDecoders{}
+	err := Load(context.Background(), &cfg, MapSource{logLevelEnv: badLevel, regionEnv: badRegion})
+	assert.ErrorIs(t, err, errBadLevel)
+	assert.ErrorIs(t, err, almierrors.ErrConversion)
+
+	var errs almierrors.Errors
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 2)
+}
+
+func TestDecoderFor_NoDecoder(t *testing.T) {
+	assert.Nil(t, decoderFor(reflect.TypeOf(testDBConfig{})))
+	assert.NotNil(t, decoderFor(reflect.TypeOf(timeV)))
+}
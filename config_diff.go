@@ -0,0 +1,165 @@
+package almiconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+const (
+	redacted        = "[redacted]"
+	nilValue        = "<nil>"
+	changeFormat    = "%s: %s -> %s"
+	restartRequired = " (restart required)"
+	changedPathSep  = ", "
+)
+
+// Change is a field that differs between two configs. The values are formatted for logging,
+// the values of 'secret' fields are redacted.
+type Change struct {
+	// Path is the dotted path of the field in the config struct.
+	Path string
+	Old  string
+	New  string
+	// Reloadable is set when the field has the 'reloadable' constraint and can be changed without a restart.
+	Reloadable bool
+	// Secret is set when the field has the 'secret' constraint, Old and New are redacted then.
+	Secret bool
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf(changeFormat, c.Path, c.Old, c.New)
+	if !c.Reloadable {
+		s += restartRequired
+	}
+
+	return s
+}
+
+// Changes are the fields that differ between two configs, in the order of the fields of the config struct.
+type Changes []Change
+
+// RestartRequired reports whether any of the changed fields is not 'reloadable'.
+func (cs Changes) RestartRequired() bool {
+	return len(cs.NotReloadable()) != 0
+}
+
+// NotReloadable returns the changes of the fields that are not 'reloadable'.
+func (cs Changes) NotReloadable() Changes {
+	var changes Changes
+	for _, c := range cs {
+		if !c.Reloadable {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes
+}
+
+// Paths returns the paths of the changed fields.
+func (cs Changes) Paths() []string {
+	paths := make([]string, 0, len(cs))
+	for _, c := range cs {
+		paths = append(paths, c.Path)
+	}
+
+	return paths
+}
+
+// String formats the changes with one field per line, so they can be logged after a reload.
+func (cs Changes) String() string {
+	lines := make([]string, 0, len(cs))
+	for _, c := range cs {
+		lines = append(lines, c.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Diff compares two configs field by field and returns the fields that differ, descending into nested,
+// embedded and pointer structs the way Load does. A nil config or pointer struct is compared as its zero value.
+func Diff[T any](old, new *T) (Changes, error) {
+	t := reflect.TypeOf(old).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, almierrors.InvalidConfigErr.Build(reflect.TypeOf(old))
+	}
+
+	var changes Changes
+	if err := diffStruct(&changes, structOrZero(t, reflect.ValueOf(old)), structOrZero(t, reflect.ValueOf(new)), consts.EMPTY); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func diffStruct(changes *Changes, old, new reflect.Value, path string) error {
+	for i := 0; i < old.NumField(); i++ {
+		val := getConfigValueByIndex(old, i, path)
+		if !val.Field.IsExported() && (!val.Field.Anonymous || val.isPointer()) {
+			continue
+		}
+
+		cc := newConfigConstraint(val)
+		if err := cc.parseConstraints(val.Constraints); err != nil {
+			return almierrors.NewFieldError(val.Path, cc.EnvName, consts.EMPTY, err)
+		}
+
+		newField := new.Field(i)
+		if val.isStruct() {
+			if err := cc.checkStructConstraints(val); err != nil {
+				return almierrors.NewFieldError(val.Path, cc.EnvName, consts.EMPTY, err)
+			}
+
+			nestedPath := val.Path
+			if val.Field.Anonymous {
+				nestedPath = path
+			}
+
+			t := val.valueType()
+			if err := diffStruct(changes, structOrZero(t, val.Value), structOrZero(t, newField), nestedPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if reflect.DeepEqual(val.Value.Interface(), newField.Interface()) {
+			continue
+		}
+
+		change := Change{Path: val.Path, Old: redacted, New: redacted, Reloadable: cc.Reloadable, Secret: cc.Secret}
+		if !cc.Secret {
+			change.Old = formatValue(val.Value)
+			change.New = formatValue(newField)
+		}
+		*changes = append(*changes, change)
+	}
+
+	return nil
+}
+
+// structOrZero is the struct v is or points to, or the zero value of t when v is a nil pointer.
+func structOrZero(t reflect.Type, v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.New(t).Elem()
+		}
+		return v.Elem()
+	}
+
+	return v
+}
+
+// formatValue formats the value of a field, the value a pointer field points to or <nil>.
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nilValue
+		}
+		v = v.Elem()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,96 @@
+package almiconfig
+
+import (
+	"testing"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	diffOldHost      = "postgres"
+	diffNewHost      = "replica"
+	diffOldPassword  = "old_password"
+	diffNewPassword  = "new_password"
+	diffOldLevel     = "info"
+	diffNewLevel     = "debug"
+	diffHostPath     = "Postgres.Host"
+	diffPasswordPath = "Postgres.Password"
+	diffLevelPath    = "LogLevel"
+	diffLimitsPath   = "Limits"
+	diffTimeoutPath  = "Timeout"
+	diffReplicaPath  = "Replica.Host"
+	diffTimeout      = 5
+	diffChanges      = "LogLevel: info -> debug\n" +
+		"Postgres.Host: postgres -> replica (restart required)\n" +
+		"Postgres.Password: [redacted] -> [redacted] (restart required)\n" +
+		"Limits: map[a:1] -> map[a:2]\n" +
+		"Timeout: <nil> -> 5\n" +
+		"Replica.Host:  -> replica (restart required)"
+)
+
+type testDiffDBConfig struct {
+	Host     string `almi:"env=HOST"`
+	Password string `almi:"env=PASSWORD,secret"`
+}
+
+type testDiffEmbedded struct {
+	LogLevel string `almi:"env=LOG_LEVEL,reloadable"`
+}
+
+type testDiffConfig struct {
+	testDiffEmbedded
+	Postgres testDiffDBConfig `almi:"prefix=PG_"`
+	Limits   map[string]int   `almi:"env=LIMITS,type={,=},reloadable"`
+	Timeout  *int             `almi:"env=TIMEOUT,reloadable"`
+	Replica  *testDiffDBConfig
+}
+
+func TestDiff_Successful(t *testing.T) {
+	timeout := diffTimeout
+	old := testDiffConfig{
+		testDiffEmbedded: testDiffEmbedded{LogLevel: diffOldLevel},
+		Postgres:         testDiffDBConfig{Host: diffOldHost, Password: diffOldPassword},
+		Limits:           map[string]int{"a": 1},
+	}
+	new := testDiffConfig{
+		testDiffEmbedded: testDiffEmbedded{LogLevel: diffNewLevel},
+		Postgres:         testDiffDBConfig{Host: diffNewHost, Password: diffNewPassword},
+		Limits:           map[string]int{"a": 2},
+		Timeout:          &timeout,
+		Replica:          &testDiffDBConfig{Host: diffNewHost},
+	}
+
+	changes, err := Diff(&old, &new)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{diffLevelPath, diffHostPath, diffPasswordPath, diffLimitsPath, diffTimeoutPath, diffReplicaPath}, changes.Paths())
+	assert.Equal(t, diffChanges, changes.String())
+	assert.NotContains(t, changes.String(), diffNewPassword)
+	assert.True(t, changes.RestartRequired())
+	assert.Equal(t, []string{diffHostPath, diffPasswordPath, diffReplicaPath}, changes.NotReloadable().Paths())
+
+	changes, err = Diff(&old, &old)
+	assert.Nil(t, err)
+	assert.Empty(t, changes)
+	assert.False(t, changes.RestartRequired())
+
+	changes, err = Diff(nil, &old)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{diffLevelPath, diffHostPath, diffPasswordPath, diffLimitsPath}, changes.Paths())
+}
+
+func TestDiff_Fail(t *testing.T) {
+	_, err := Diff(&struct {
+		Postgres testDiffDBConfig `almi:"reloadable"`
+	}{}, nil)
+	assert.ErrorIs(t, err, almierrors.StructConstraintErr)
+
+	_, err = Diff(&struct {
+		Host string `almi:"env=HOST,reload"`
+	}{}, nil)
+	assert.ErrorIs(t, err, almierrors.ConstraintUnknownErr)
+
+	value := 1
+	_, err = Diff(&value, &value)
+	assert.ErrorIs(t, err, almierrors.InvalidConfigErr)
+}
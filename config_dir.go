@@ -0,0 +1,129 @@
+package almiconfig
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+const (
+	dirSourceName = "dir"
+
+	// k8sDataDir is the symlink Kubernetes points to the current version of a mounted ConfigMap or Secret,
+	// it is swapped atomically on updates and the names of the mounted files are symlinks through it.
+	k8sDataDir = "..data"
+	// hiddenPrefix marks the files skipped in a config directory, like the timestamped directories of Kubernetes.
+	hiddenPrefix = "."
+)
+
+// ReadDirectory reads a directory tree where every file name is a key and the contents of the file its value,
+// like the ConfigMaps and Secrets Kubernetes mounts into a pod. Subdirectories are looked up as nested structs,
+// so postgres/host is the value of Postgres.Host, and files are looked up by the env name of their field as well.
+// Trailing newlines are trimmed from the values and hidden files are skipped.
+//
+// When the directory holds the '..data' symlink of a Kubernetes mount, the files are read from the directory
+// it points to, so a single read sees one version of the mount even while Kubernetes swaps it.
+// Files larger than 1 MiB are reported as an almierrors.DirFileTooLargeErr error.
+func ReadDirectory(path string) (*DocumentSource, error) {
+	doc, err := readDirectory(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DocumentSource{name: dirSourceName, doc: doc}, nil
+}
+
+// readDirectory reads the tree below dir into nested maps, one for every subdirectory.
+func readDirectory(dir string) (map[string]any, error) {
+	doc := map[string]any{}
+	err := walkTree(dir, func(keys []string, path string, info os.FileInfo) error {
+		if len(keys) == 0 {
+			return nil
+		}
+
+		parent := doc
+		for _, key := range keys[:len(keys)-1] {
+			parent = parent[key].(map[string]any)
+		}
+
+		if info.IsDir() {
+			parent[keys[len(keys)-1]] = map[string]any{}
+			return nil
+		}
+
+		val, ok, err := readValueFile(path, defaultFileSizeLimit)
+		switch {
+		case err != nil:
+			return almierrors.DirReadErr.Wrap(err, path)
+		case !ok:
+			return almierrors.DirFileTooLargeErr.Build(path, defaultFileSizeLimit)
+		}
+		parent[keys[len(keys)-1]] = val
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// walkTree calls fn for root and every file and directory below it the way ReadDirectory reads them,
+// through the '..data' symlink of a Kubernetes mount, following symlinks and skipping hidden files.
+// keys is the path of the entry below root, directories are walked before their contents.
+func walkTree(root string, fn func(keys []string, path string, info os.FileInfo) error) error {
+	return walkDir(root, nil, map[string]bool{}, fn)
+}
+
+// walkDir walks the entry at path, visited holds the directories walked so far to break symlink loops.
+func walkDir(path string, keys []string, visited map[string]bool, fn func([]string, string, os.FileInfo) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return almierrors.DirReadErr.Wrap(err, path)
+	}
+
+	if err := fn(keys, path, info); err != nil || !info.IsDir() {
+		return err
+	}
+
+	if data := filepath.Join(path, k8sDataDir); exists(data) {
+		path = data
+	}
+
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return almierrors.DirReadErr.Wrap(err, path)
+	}
+
+	if visited[real] {
+		return nil
+	}
+	visited[real] = true
+
+	entries, err := os.ReadDir(real)
+	if err != nil {
+		return almierrors.DirReadErr.Wrap(err, path)
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), hiddenPrefix) {
+			continue
+		}
+
+		// symlinks are followed, Kubernetes mounts every key as a symlink
+		if err := walkDir(filepath.Join(real, entry.Name()), append(slices.Clip(keys), entry.Name()), visited, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
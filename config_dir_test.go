@@ -0,0 +1,132 @@
+package almiconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dirPostgres       = "postgres"
+	dirHost           = "host"
+	dirTimestamp      = "..2026_10_18_12_00_00.000000001"
+	dirNextTimestamp  = "..2026_10_18_12_05_00.000000001"
+	dirDataTmp        = "..data_tmp"
+	dirPassword       = "secret\n"
+	dirPasswordV      = "secret"
+	dirNextPasswordV  = "rotated"
+	dirHostPath       = "Postgres.Host"
+	dirFilePerm       = 0o600
+	dirPerm           = 0o700
+	dirPasswordEnvKey = "PG_PASSWORD"
+)
+
+type testDirDBConfig struct {
+	Host     string `almi:"required"`
+	Port     int    `almi:"env=PG_PORT"`
+	Password string `almi:"required,env=PG_PASSWORD"`
+}
+
+type testDirConfig struct {
+	Postgres testDirDBConfig
+}
+
+func writeDirFile(t *testing.T, path, contents string) {
+	t.Helper()
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), dirPerm))
+	assert.Nil(t, os.WriteFile(path, []byte(contents), dirFilePerm))
+}
+
+// mountVersion writes a version of a Kubernetes volume into a timestamped directory of dir
+// and swaps the '..data' symlink to it.
+func mountVersion(t *testing.T, dir, version, password string) {
+	t.Helper()
+	writeDirFile(t, filepath.Join(dir, version, dirPasswordEnvKey), password)
+	writeDirFile(t, filepath.Join(dir, version, dirPostgres, dirHost), pgHost)
+	writeDirFile(t, filepath.Join(dir, version, pgPortEnv), pgPort)
+
+	assert.Nil(t, os.Symlink(version, filepath.Join(dir, dirDataTmp)))
+	assert.Nil(t, os.Rename(filepath.Join(dir, dirDataTmp), filepath.Join(dir, k8sDataDir)))
+}
+
+func TestReadDirectory_Successful(t *testing.T) {
+	dir := t.TempDir()
+	writeDirFile(t, filepath.Join(dir, dirPasswordEnvKey), dirPassword)
+	writeDirFile(t, filepath.Join(dir, dirPostgres, dirHost), pgHost)
+	writeDirFile(t, filepath.Join(dir, pgPortEnv), pgPort)
+	writeDirFile(t, filepath.Join(dir, hiddenPrefix+pgHostEnv), pgHost)
+
+	src, err := ReadDirectory(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, dirSourceName, src.Name())
+
+	_, ok := src.Lookup(hiddenPrefix + pgHostEnv)
+	assert.False(t, ok)
+
+	l := NewLoader(WithSources(src))
+	cfg := testDirConfig{}
+	report, err := l.Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, pgHost, cfg.Postgres.Host)
+	assert.Equal(t, pgPortValue, cfg.Postgres.Port)
+	assert.Equal(t, dirPasswordV, cfg.Postgres.Password)
+
+	origin, _ := report.Origin(dirHostPath)
+	assert.Equal(t, dirSourceName, origin.Source)
+}
+
+func TestReadDirectory_Successful_KubernetesMount(t *testing.T) {
+	dir := t.TempDir()
+	mountVersion(t, dir, dirTimestamp, dirPassword)
+
+	// the keys are mounted as symlinks through '..data'
+	for _, key := range []string{dirPasswordEnvKey, dirPostgres, pgPortEnv} {
+		assert.Nil(t, os.Symlink(filepath.Join(k8sDataDir, key), filepath.Join(dir, key)))
+	}
+
+	src, err := ReadDirectory(dir)
+	assert.Nil(t, err)
+
+	cfg := testDirConfig{}
+	assert.Nil(t, Load(context.Background(), &cfg, src))
+	assert.Equal(t, pgHost, cfg.Postgres.Host)
+	assert.Equal(t, dirPasswordV, cfg.Postgres.Password)
+
+	mountVersion(t, dir, dirNextTimestamp, dirNextPasswordV)
+
+	src, err = ReadDirectory(dir)
+	assert.Nil(t, err)
+
+	cfg = testDirConfig{}
+	assert.Nil(t, Load(context.Background(), &cfg, src))
+	assert.Equal(t, dirNextPasswordV, cfg.Postgres.Password)
+}
+
+func TestReadDirectory_Successful_SymlinkLoop(t *testing.T) {
+	dir := t.TempDir()
+	writeDirFile(t, filepath.Join(dir, dirPostgres, dirHost), pgHost)
+	assert.Nil(t, os.Symlink(dir, filepath.Join(dir, dirPostgres, dirPostgres)))
+
+	src, err := ReadDirectory(dir)
+	assert.Nil(t, err)
+
+	val, ok := src.LookupPath([]string{dirPostgres, dirHost})
+	assert.True(t, ok)
+	assert.Equal(t, pgHost, val)
+}
+
+func TestReadDirectory_Fail(t *testing.T) {
+	_, err := ReadDirectory(filepath.Join(t.TempDir(), dirPostgres))
+	assert.ErrorIs(t, err, almierrors.DirReadErr)
+
+	dir := t.TempDir()
+	writeDirFile(t, filepath.Join(dir, dirPasswordEnvKey), strings.Repeat(pgHost, int(defaultFileSizeLimit)/len(pgHost)+1))
+
+	_, err = ReadDirectory(dir)
+	assert.ErrorIs(t, err, almierrors.DirFileTooLargeErr)
+}
@@ -0,0 +1,354 @@
+package almiconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	jsonSourceName = "json"
+	yamlSourceName = "yaml"
+	tomlSourceName = "toml"
+
+	pathSep = "."
+
+	documentRootMsg = "the document must be an object at the top level"
+)
+
+// PathSource is a Source of structured documents, like JSON, YAML, TOML or INI files, fields are looked up in it
+// by their document path instead of their env name. The path of a field is the path of its nested struct fields,
+// or the names set with 'key=', like [Postgres Host].
+type PathSource interface {
+	Source
+	LookupPath(path []string) (any, bool)
+}
+
+// DocumentSource reads the values of a JSON, YAML, TOML or INI document. The keys of the document are matched
+// to the path of a field exactly, or otherwise ignoring case, '_' and '-', so MaxIdleConns matches max_idle_conns.
+// Arrays and objects are joined with the separators of their field, so they are converted like env values.
+type DocumentSource struct {
+	name string
+	doc  map[string]any
+}
+
+// ReadJSON parses the JSON file at path.
+func ReadJSON(path string) (*DocumentSource, error) {
+	return readDocument(path, parseJSON)
+}
+
+// ParseJSON parses a JSON document read from r, syntax errors are reported as an *almierrors.SyntaxError.
+func ParseJSON(r io.Reader) (*DocumentSource, error) {
+	return parseDocument(jsonSourceName, r, parseJSON)
+}
+
+// ReadYAML parses the YAML file at path.
+func ReadYAML(path string) (*DocumentSource, error) {
+	return readDocument(path, parseYAML)
+}
+
+// ParseYAML parses a YAML document read from r, syntax errors are reported as an *almierrors.SyntaxError.
+func ParseYAML(r io.Reader) (*DocumentSource, error) {
+	return parseDocument(yamlSourceName, r, parseYAML)
+}
+
+// ReadTOML parses the TOML file at path.
+func ReadTOML(path string) (*DocumentSource, error) {
+	return readDocument(path, parseTOML)
+}
+
+// ParseTOML parses a TOML document read from r, tables and inline tables are looked up as nested structs or maps
+// and arrays as slices. Syntax errors are reported as an *almierrors.SyntaxError.
+func ParseTOML(r io.Reader) (*DocumentSource, error) {
+	return parseDocument(tomlSourceName, r, parseTOML)
+}
+
+type documentParser func(file string, data []byte) (*DocumentSource, error)
+
+func readDocument(path string, parse documentParser) (*DocumentSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(path, data)
+}
+
+func parseDocument(file string, r io.Reader, parse documentParser) (*DocumentSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(file, data)
+}
+
+func parseJSON(file string, data []byte) (*DocumentSource, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// numbers are kept as written, so large integers don't lose precision through float64
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, col := position(data, int(syntaxErr.Offset))
+			return nil, &almierrors.SyntaxError{File: file, Line: line, Column: col, Msg: syntaxErr.Error()}
+		}
+		return nil, err
+	}
+
+	return newDocumentSource(jsonSourceName, file, doc)
+}
+
+func parseYAML(file string, data []byte) (*DocumentSource, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, &almierrors.SyntaxError{File: file, Line: yamlErrorLine(err), Column: 1, Msg: err.Error()}
+	}
+
+	var doc any
+	if err := root.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	// an empty document has no root node
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	return newDocumentSource(yamlSourceName, file, doc)
+}
+
+func parseTOML(file string, data []byte) (*DocumentSource, error) {
+	doc := map[string]any{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &almierrors.SyntaxError{File: file, Line: parseErr.Position.Line, Column: parseErr.Position.Col, Msg: parseErr.Message}
+		}
+		return nil, err
+	}
+
+	return newDocumentSource(tomlSourceName, file, doc)
+}
+
+func newDocumentSource(name, file string, doc any) (*DocumentSource, error) {
+	obj, ok := asObject(doc)
+	if !ok {
+		return nil, &almierrors.SyntaxError{File: file, Line: 1, Column: 1, Msg: documentRootMsg}
+	}
+
+	return &DocumentSource{name: name, doc: obj}, nil
+}
+
+// position converts a byte offset into data to a 1-based line and column.
+func position(data []byte, offset int) (int, int) {
+	offset = min(offset, len(data))
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	col := offset - bytes.LastIndexByte(data[:offset], '\n')
+
+	return line, col
+}
+
+// yamlErrorLine reads the line from the 'yaml: line N: ...' messages of yaml.v3, it is 1 when the message has none.
+func yamlErrorLine(err error) int {
+	var line int
+	if _, scanErr := fmt.Sscanf(err.Error(), "yaml: line %d:", &line); scanErr != nil {
+		return 1
+	}
+
+	return line
+}
+
+func (ds *DocumentSource) Name() string {
+	return ds.name
+}
+
+// Lookup looks up a dotted document path, like postgres.host, arrays and objects are joined with ',' and ':'.
+// Values whose elements hold the separators can't be joined and are treated as missing, LookupPath returns them as they are.
+func (ds *DocumentSource) Lookup(key string) (string, bool) {
+	val, ok := ds.LookupPath(strings.Split(key, pathSep))
+	if !ok {
+		return consts.EMPTY, false
+	}
+
+	flat, err := flatten(val, defaultSeparator, defaultKVSeparator)
+	return flat, err == nil
+}
+
+// LookupPath returns the value at path, null values are treated as missing.
+func (ds *DocumentSource) LookupPath(path []string) (any, bool) {
+	var cur any = ds.doc
+	for _, segment := range path {
+		obj, ok := asObject(cur)
+		if !ok {
+			return nil, false
+		}
+
+		if cur, ok = lookupKey(obj, segment); !ok {
+			return nil, false
+		}
+	}
+
+	return cur, cur != nil && len(path) != 0
+}
+
+// lookupKey looks key up in obj exactly, or otherwise ignoring case, '_' and '-'.
+func lookupKey(obj map[string]any, key string) (any, bool) {
+	if val, ok := obj[key]; ok {
+		return val, true
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	// sorted, so the match is the same every time when several keys only differ in case
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		if normalizeKey(k) == normalizeKey(key) {
+			return obj[k], true
+		}
+	}
+
+	return nil, false
+}
+
+// asObject returns val as an object, YAML decodes mappings with keys that aren't strings, like 80: http,
+// as map[any]any and their keys are formatted as strings.
+func asObject(val any) (map[string]any, bool) {
+	switch v := val.(type) {
+	case map[string]any:
+		return v, true
+	case map[any]any:
+		obj := make(map[string]any, len(v))
+		for k, elem := range v {
+			obj[fmt.Sprint(k)] = elem
+		}
+		return obj, true
+	default:
+		return nil, false
+	}
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", consts.EMPTY, "-", consts.EMPTY).Replace(key))
+}
+
+// flatten formats a document value as the string an env variable would hold for it, arrays are joined by sep
+// and objects are written as key-value pairs joined by kvSep, sorted by key.
+// Elements holding sep, and keys holding sep or kvSep, would be split apart again when the string is converted,
+// they are reported with an almierrors.DocumentSeparatorErr instead of being silently split.
+func flatten(val any, sep, kvSep string) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case []any:
+		elems := make([]string, 0, len(v))
+		for _, elem := range v {
+			flat, err := flattenElem(elem, sep, kvSep)
+			if err != nil {
+				return consts.EMPTY, err
+			}
+			elems = append(elems, flat)
+		}
+		return strings.Join(elems, sep), nil
+	case map[any]any:
+		obj, _ := asObject(v)
+		return flatten(obj, sep, kvSep)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		pairs := make([]string, 0, len(v))
+		for _, k := range keys {
+			if strings.Contains(k, sep) || strings.Contains(k, kvSep) {
+				return consts.EMPTY, almierrors.DocumentSeparatorErr.Build(k, sep+kvSep)
+			}
+
+			flat, err := flattenElem(v[k], sep, kvSep)
+			if err != nil {
+				return consts.EMPTY, err
+			}
+			pairs = append(pairs, k+kvSep+flat)
+		}
+		return strings.Join(pairs, sep), nil
+	case nil:
+		return consts.EMPTY, nil
+	default:
+		// arrays of tables and the like have no env variable form, Go syntax would be loaded as the value
+		if composite(v) {
+			return consts.EMPTY, almierrors.DocumentValueErr.Build(v)
+		}
+		return fmt.Sprint(v), nil
+	}
+}
+
+// flattenElem flattens an element of an array or a value of an object, which must not hold sep.
+func flattenElem(elem any, sep, kvSep string) (string, error) {
+	if composite(elem) {
+		return consts.EMPTY, almierrors.DocumentValueErr.Build(elem)
+	}
+
+	flat, err := flatten(elem, sep, kvSep)
+	if err != nil {
+		return consts.EMPTY, err
+	}
+
+	if strings.Contains(flat, sep) {
+		return consts.EMPTY, almierrors.DocumentSeparatorErr.Build(flat, sep)
+	}
+
+	return flat, nil
+}
+
+// composite reports whether val is an array, an object or another value that isn't a scalar of a document.
+func composite(val any) bool {
+	if _, ok := val.(time.Time); ok {
+		return false
+	}
+
+	switch reflect.ValueOf(val).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Pointer, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// asPathSource returns src as a PathSource, looking through the names given with Named and watched sources.
+func asPathSource(src Source) (PathSource, bool) {
+	if ns, ok := src.(*namedSource); ok {
+		src = ns.Source
+	}
+	if ws, ok := src.(*WatchedSource); ok {
+		src = ws.source()
+	}
+
+	ps, ok := src.(PathSource)
+	return ps, ok
+}
@@ -0,0 +1,335 @@
+package almiconfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	yamlDocument = `
+postgres:
+  host: postgres
+  port: 5432
+  max_idle_conns: 10
+timeout: 5s
+brokers:
+  - broker1
+  - broker2
+limits:
+  tenant1: 100
+  tenant2: 250
+feature-flags:
+  beta: true
+`
+	jsonDocument = `{
+  "Postgres": {"Host": "postgres", "Port": 5432, "MaxIdleConns": 10},
+  "timeout": "5s",
+  "brokers": ["broker1", "broker2"],
+  "limits": {"tenant1": 100, "tenant2": 250},
+  "feature-flags": {"beta": true},
+  "big": 9007199254740993
+}`
+	jsonBadDocument       = "{\n  \"a\": 1,\n  \"b\": ]\n}"
+	yamlBadDocument       = "a: 1\nb: [\n"
+	jsonArrayDocument     = "[1, 2]"
+	jsonSeparatorDocument = `{"hosts": ["a,b", "c"], "limits": {"a:b": 1}}`
+	yamlIntKeysDocument   = "postgres: {host: postgres, ports: {80: http, 443: https}}\n"
+	yamlNestedDocument    = "hosts:\n  - [a, b]\nlimits: {a: {b: 1}}\n"
+	yamlBadPortsDocument  = "postgres: {ports: {http: 80}}\n"
+	documentPortsPath     = "Postgres.Ports"
+	documentEnvPort       = "6543"
+	documentEnvPortV      = int(6543)
+	documentPortPath      = "Postgres.Port"
+	documentBrokersKey    = "brokers"
+	documentBig           = "9007199254740993"
+)
+
+type testDocumentDBConfig struct {
+	Host         string `almi:"required"`
+	Port         int    `almi:"env=PG_PORT"`
+	MaxIdleConns int
+}
+
+type testDocumentConfig struct {
+	Postgres testDocumentDBConfig
+	Timeout  time.Duration
+	Brokers  []string
+	Limits   map[string]int
+	Beta     bool `almi:"key=feature-flags.beta"`
+}
+
+var (
+	documentBrokers = []string{"broker1", "broker2"}
+	documentLimits  = map[string]int{"tenant1": 100, "tenant2": 250}
+)
+
+func TestLoad_Successful_Documents(t *testing.T) {
+	yamlSrc, err := ParseYAML(strings.NewReader(yamlDocument))
+	assert.Nil(t, err)
+	jsonSrc, err := ParseJSON(strings.NewReader(jsonDocument))
+	assert.Nil(t, err)
+
+	for _, src := range []Source{yamlSrc, jsonSrc} {
+		cfg := testDocumentConfig{}
+		err := Load(context.Background(), &cfg, src)
+		assert.Nil(t, err)
+		assert.Equal(t, pgHost, cfg.Postgres.Host)
+		assert.Equal(t, pgPortValue, cfg.Postgres.Port)
+		assert.Equal(t, maxIdleConnsV, cfg.Postgres.MaxIdleConns)
+		assert.Equal(t, 5*time.Second, cfg.Timeout)
+		assert.Equal(t, documentBrokers, cfg.Brokers)
+		assert.Equal(t, documentLimits, cfg.Limits)
+		assert.True(t, cfg.Beta)
+	}
+}
+
+func TestLoader_Load_Successful_EnvOverridesDocument(t *testing.T) {
+	yamlSrc, err := ParseYAML(strings.NewReader(yamlDocument))
+	assert.Nil(t, err)
+
+	cfg := testDocumentConfig{}
+	l := NewLoader(WithSources(MapSource{pgPortEnv: documentEnvPort}, yamlSrc))
+	report, err := l.Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, documentEnvPortV, cfg.Postgres.Port)
+	assert.Equal(t, pgHost, cfg.Postgres.Host)
+
+	origin, _ := report.Origin(documentPortPath)
+	assert.Equal(t, pgPortEnv, origin.Key)
+	assert.Equal(t, mapSourceName, origin.Source)
+
+	report, err = NewLoader(WithSources(yamlSrc)).Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	origin, _ = report.Origin(documentPortPath)
+	assert.Equal(t, FieldOrigin{Path: documentPortPath, Key: documentPortPath, Source: yamlSourceName}, origin)
+}
+
+func TestDocumentSource_Lookup(t *testing.T) {
+	jsonSrc, err := ParseJSON(strings.NewReader(jsonDocument))
+	assert.Nil(t, err)
+
+	val, ok := jsonSrc.Lookup(documentBrokersKey)
+	assert.True(t, ok)
+	assert.Equal(t, strings.Join(documentBrokers, comma), val)
+
+	val, ok = jsonSrc.Lookup("big")
+	assert.True(t, ok)
+	assert.Equal(t, documentBig, val)
+
+	_, ok = jsonSrc.Lookup(unknownFieldPath)
+	assert.False(t, ok)
+}
+
+func TestLoad_Fail_DocumentRequiredField(t *testing.T) {
+	jsonSrc, err := ParseJSON(strings.NewReader("{}"))
+	assert.Nil(t, err)
+
+	cfg := testDocumentConfig{}
+	err = Load(context.Background(), &cfg, jsonSrc)
+	assert.ErrorIs(t, err, almierrors.FieldRequiredErr)
+}
+
+func TestLoad_Successful_DocumentElementsKeepBoundaries(t *testing.T) {
+	jsonSrc, err := ParseJSON(strings.NewReader(jsonSeparatorDocument))
+	assert.Nil(t, err)
+
+	cfg := struct {
+		Hosts []string `almi:"type=[;]string"`
+	}{}
+	err = Load(context.Background(), &cfg, jsonSrc)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a,b", "c"}, cfg.Hosts)
+}
+
+func TestLoad_Fail_DocumentElementHoldsSeparator(t *testing.T) {
+	jsonSrc, err := ParseJSON(strings.NewReader(jsonSeparatorDocument))
+	assert.Nil(t, err)
+
+	cfg := struct {
+		Hosts  []string
+		Limits map[string]int `almi:"type={,:}"`
+	}{}
+	err = Load(context.Background(), &cfg, jsonSrc)
+
+	var errs almierrors.Errors
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[0], almierrors.DocumentSeparatorErr)
+	assert.ErrorIs(t, errs[0], almierrors.ErrConversion)
+	assert.ErrorIs(t, errs[1], almierrors.DocumentSeparatorErr)
+	assert.Nil(t, cfg.Hosts)
+}
+
+func TestLoad_Successful_YAMLIntKeys(t *testing.T) {
+	yamlSrc, err := ParseYAML(strings.NewReader(yamlIntKeysDocument))
+	assert.Nil(t, err)
+
+	cfg := struct {
+		Postgres struct {
+			Host  string `almi:"required"`
+			Ports map[int]string
+		}
+	}{}
+	err = Load(context.Background(), &cfg, yamlSrc)
+	assert.Nil(t, err)
+	assert.Equal(t, pgHost, cfg.Postgres.Host)
+	assert.Equal(t, map[int]string{80: "http", 443: "https"}, cfg.Postgres.Ports)
+}
+
+func TestLoad_Fail_DocumentFieldErrorNamesPath(t *testing.T) {
+	yamlSrc, err := ParseYAML(strings.NewReader(yamlBadPortsDocument))
+	assert.Nil(t, err)
+
+	cfg := struct {
+		Postgres struct {
+			Ports map[int]string
+		}
+	}{}
+	err = Load(context.Background(), &cfg, yamlSrc)
+
+	var fieldErr *almierrors.FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, documentPortsPath, fieldErr.Key)
+	assert.ErrorIs(t, err, almierrors.ErrConversion)
+	assert.Contains(t, err.Error(), "'"+documentPortsPath+"'")
+	assert.NotContains(t, err.Error(), "''")
+}
+
+func TestLoad_Fail_DocumentNestedValues(t *testing.T) {
+	yamlSrc, err := ParseYAML(strings.NewReader(yamlNestedDocument))
+	assert.Nil(t, err)
+
+	cfg := struct {
+		Hosts  []string
+		Limits map[string]string
+	}{}
+	err = Load(context.Background(), &cfg, yamlSrc)
+
+	var errs almierrors.Errors
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[0], almierrors.DocumentValueErr)
+	assert.ErrorIs(t, errs[0], almierrors.ErrConversion)
+	assert.ErrorIs(t, errs[1], almierrors.DocumentValueErr)
+	assert.Nil(t, cfg.Hosts)
+}
+
+func TestParseDocument_Fail_SyntaxErrors(t *testing.T) {
+	var syntaxErr *almierrors.SyntaxError
+
+	_, err := ParseJSON(strings.NewReader(jsonBadDocument))
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 3, syntaxErr.Line)
+
+	_, err = ParseYAML(strings.NewReader(yamlBadDocument))
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 2, syntaxErr.Line)
+
+	_, err = ParseJSON(strings.NewReader(jsonArrayDocument))
+	assert.ErrorIs(t, err, almierrors.SyntaxErr)
+}
+
+const (
+	tomlDocument = `
+timeout = "5s"
+brokers = ["broker1", "broker2"]
+limits = { tenant1 = 100, tenant2 = 250 }
+
+[postgres]
+host = "postgres"
+port = 5432
+max_idle_conns = 10
+
+[feature-flags]
+beta = true
+`
+	iniDocument = `
+; top level keys
+timeout = 5s
+brokers = broker1,broker2
+limits = tenant1:100,tenant2:250
+
+[postgres]
+host = "postgres" ; quoted
+port = 5432 # the default port
+max_idle_conns = 10
+
+[feature-flags]
+beta = true
+`
+	iniReplicaDocument = "[postgres.replica]\nhost = replica\n"
+	iniReplicaHostKey  = "postgres.replica.host"
+	iniReplicaHost     = "replica"
+	tomlBadDocument    = "a = 1\nb = [1,\nc = 2"
+	tomlTablesDocument = "[[servers]]\nname = 'x'\n\n[[servers]]\nname = 'y'\n"
+)
+
+func TestLoad_Successful_TOMLAndINI(t *testing.T) {
+	tomlSrc, err := ParseTOML(strings.NewReader(tomlDocument))
+	assert.Nil(t, err)
+	iniSrc, err := ParseINI(strings.NewReader(iniDocument))
+	assert.Nil(t, err)
+
+	for _, src := range []Source{tomlSrc, iniSrc} {
+		cfg := testDocumentConfig{}
+		err := Load(context.Background(), &cfg, MapSource{pgPortEnv: documentEnvPort}, src)
+		assert.Nil(t, err)
+		assert.Equal(t, pgHost, cfg.Postgres.Host)
+		assert.Equal(t, documentEnvPortV, cfg.Postgres.Port)
+		assert.Equal(t, maxIdleConnsV, cfg.Postgres.MaxIdleConns)
+		assert.Equal(t, 5*time.Second, cfg.Timeout)
+		assert.Equal(t, documentBrokers, cfg.Brokers)
+		assert.Equal(t, documentLimits, cfg.Limits)
+		assert.True(t, cfg.Beta)
+	}
+}
+
+func TestParseINI_Successful_NestedSections(t *testing.T) {
+	iniSrc, err := ParseINI(strings.NewReader(iniReplicaDocument))
+	assert.Nil(t, err)
+
+	val, ok := iniSrc.Lookup(iniReplicaHostKey)
+	assert.True(t, ok)
+	assert.Equal(t, iniReplicaHost, val)
+}
+
+func TestParseTOMLAndINI_Fail_SyntaxErrors(t *testing.T) {
+	var syntaxErr *almierrors.SyntaxError
+
+	_, err := ParseTOML(strings.NewReader(tomlBadDocument))
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 3, syntaxErr.Line)
+
+	lines := map[string]int{
+		"a = 1\n[section":     2,
+		"a = 1\n\nb":          3,
+		"[a]\n[a..b]":         2,
+		"a = 1\n[a]":          2,
+		"[a]\nb = 1\n[a.b]\n": 3,
+		"= 1":                 1,
+	}
+	for doc, line := range lines {
+		_, err := ParseINI(strings.NewReader(doc))
+		assert.True(t, errors.As(err, &syntaxErr), doc)
+		assert.Equal(t, line, syntaxErr.Line, doc)
+	}
+}
+
+func TestLoad_Fail_TOMLArrayOfTables(t *testing.T) {
+	tomlSrc, err := ParseTOML(strings.NewReader(tomlTablesDocument))
+	assert.Nil(t, err)
+
+	cfg := struct {
+		Servers []string
+	}{}
+	err = Load(context.Background(), &cfg, tomlSrc)
+	assert.ErrorIs(t, err, almierrors.DocumentValueErr)
+	assert.ErrorIs(t, err, almierrors.ErrConversion)
+	assert.Nil(t, cfg.Servers)
+}
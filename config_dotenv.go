@@ -0,0 +1,341 @@
+package almiconfig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+const (
+	dotenvSourceName = "dotenv"
+	dotenvExport     = "export"
+	dotenvBOM        = "\uFEFF"
+
+	dotenvComment     = '#'
+	dotenvAssign      = '='
+	dotenvSingleQuote = '\''
+	dotenvDoubleQuote = '"'
+	dotenvEscape      = '\\'
+	dotenvRef         = '$'
+	dotenvRefOpen     = '{'
+
+	dotenvExpectedKeyMsg      = "expected a key"
+	dotenvExpectedAssignMsg   = "expected '=' after key '%s'"
+	dotenvUnterminatedMsg     = "unterminated %c quoted value"
+	dotenvUnterminatedRefMsg  = "unterminated variable reference"
+	dotenvAfterQuotedValueMsg = "unexpected character '%c' after quoted value"
+)
+
+// dotenvEscapes are the escape sequences of double quoted values.
+var dotenvEscapes = map[rune]rune{
+	'n':               '\n',
+	'r':               '\r',
+	't':               '\t',
+	dotenvEscape:      dotenvEscape,
+	dotenvDoubleQuote: dotenvDoubleQuote,
+	dotenvRef:         dotenvRef,
+}
+
+// DotenvSource reads the values of a .env file. Unlike loading the file into the process environment,
+// it leaves os.Environ untouched.
+type DotenvSource map[string]string
+
+func (ds DotenvSource) Lookup(key string) (string, bool) {
+	val, ok := ds[key]
+	return val, ok
+}
+
+func (DotenvSource) Name() string {
+	return dotenvSourceName
+}
+
+// ReadDotenv parses the .env file at path, see ParseDotenv for its syntax.
+func ReadDotenv(path string) (DotenvSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDotenv(path, data)
+}
+
+// ParseDotenv parses a .env file read from r. Every line holds a KEY=value pair, optionally prefixed with 'export',
+// blank lines and lines starting with '#' are skipped. Values can be:
+//   - unquoted, running until the end of the line or a ' #' comment, with the surrounding whitespace trimmed,
+//   - single quoted, taken literally and spanning multiple lines,
+//   - double quoted, spanning multiple lines, with the \n, \r, \t, \\, \" and \$ escape sequences.
+//
+// Unquoted and double quoted values interpolate $VAR, ${VAR}, ${VAR:-default} and ${VAR:?error} references,
+// which are resolved against the keys defined above them and then the process environment.
+// Syntax errors are reported as an *almierrors.SyntaxError with the line and column of the problem.
+func ParseDotenv(r io.Reader) (DotenvSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDotenv(dotenvSourceName, data)
+}
+
+// dotenvParser parses a .env file rune by rune, keeping track of the line and column for syntax errors.
+type dotenvParser struct {
+	file   string
+	src    []rune
+	pos    int
+	line   int
+	col    int
+	values DotenvSource
+}
+
+func parseDotenv(file string, data []byte) (DotenvSource, error) {
+	p := &dotenvParser{
+		file:   file,
+		src:    []rune(string(bytes.TrimPrefix(data, []byte(dotenvBOM)))),
+		line:   1,
+		col:    1,
+		values: DotenvSource{},
+	}
+
+	for {
+		p.skip(unicode.IsSpace)
+		switch p.peek() {
+		case 0:
+			return p.values, nil
+		case dotenvComment:
+			p.skipLine()
+		default:
+			if err := p.parsePair(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) next() rune {
+	r := p.peek()
+	p.pos++
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+
+	return r
+}
+
+// skip consumes the runes matching f.
+func (p *dotenvParser) skip(f func(rune) bool) {
+	for !p.eof() && f(p.peek()) {
+		p.next()
+	}
+}
+
+// skipLine consumes the rest of the line, like a comment.
+func (p *dotenvParser) skipLine() {
+	p.skip(func(r rune) bool { return r != '\n' })
+}
+
+func (p *dotenvParser) errorAt(line, col int, format string, args ...any) error {
+	return &almierrors.SyntaxError{File: p.file, Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+func isKeyRune(r rune) bool {
+	return r == '_' || r == '.' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isRefNameRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (p *dotenvParser) readKey() string {
+	start := p.pos
+	p.skip(isKeyRune)
+	return string(p.src[start:p.pos])
+}
+
+func (p *dotenvParser) parsePair() error {
+	key := p.readKey()
+	if key == dotenvExport && isBlank(p.peek()) {
+		p.skip(isBlank)
+		key = p.readKey()
+	}
+
+	if key == consts.EMPTY {
+		return p.errorAt(p.line, p.col, dotenvExpectedKeyMsg)
+	}
+
+	p.skip(isBlank)
+	if p.peek() != dotenvAssign {
+		return p.errorAt(p.line, p.col, dotenvExpectedAssignMsg, key)
+	}
+	p.next()
+	p.skip(isBlank)
+
+	var (
+		val string
+		err error
+	)
+	switch p.peek() {
+	case dotenvSingleQuote:
+		val, err = p.readQuoted(dotenvSingleQuote, false)
+	case dotenvDoubleQuote:
+		val, err = p.readQuoted(dotenvDoubleQuote, true)
+	default:
+		val, err = p.readUnquoted()
+	}
+	if err != nil {
+		return err
+	}
+
+	p.values[key] = val
+	return nil
+}
+
+// readQuoted reads a value enclosed in quote, escape sequences and references are only handled when interpolate is set.
+func (p *dotenvParser) readQuoted(quote rune, interpolate bool) (string, error) {
+	line, col := p.line, p.col
+	p.next()
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return consts.EMPTY, p.errorAt(line, col, dotenvUnterminatedMsg, quote)
+		}
+
+		refLine, refCol := p.line, p.col
+		r := p.next()
+		switch {
+		case r == quote:
+			return b.String(), p.endQuoted()
+		case interpolate && r == dotenvEscape && !p.eof():
+			esc := p.next()
+			if unescaped, ok := dotenvEscapes[esc]; ok {
+				b.WriteRune(unescaped)
+			} else {
+				b.WriteRune(r)
+				b.WriteRune(esc)
+			}
+		case interpolate && r == dotenvRef:
+			if err := p.interpolate(&b, refLine, refCol); err != nil {
+				return consts.EMPTY, err
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// endQuoted makes sure nothing but a comment follows a quoted value on its line.
+func (p *dotenvParser) endQuoted() error {
+	p.skip(isBlank)
+	switch r := p.peek(); r {
+	case 0, '\n', '\r':
+		return nil
+	case dotenvComment:
+		p.skipLine()
+		return nil
+	default:
+		return p.errorAt(p.line, p.col, dotenvAfterQuotedValueMsg, r)
+	}
+}
+
+// readUnquoted reads a value up to the end of the line, a '#' at the start of the value or after whitespace starts a comment.
+func (p *dotenvParser) readUnquoted() (string, error) {
+	var b strings.Builder
+	afterBlank := true
+	for !p.eof() && p.peek() != '\n' {
+		if p.peek() == dotenvComment && afterBlank {
+			p.skipLine()
+			break
+		}
+
+		refLine, refCol := p.line, p.col
+		r := p.next()
+		afterBlank = isBlank(r)
+		if r == dotenvRef {
+			if err := p.interpolate(&b, refLine, refCol); err != nil {
+				return consts.EMPTY, err
+			}
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return strings.TrimRightFunc(b.String(), unicode.IsSpace), nil
+}
+
+// dotenvRefMsgs are the messages of the syntax errors of references, by the Expand template of the problem.
+var dotenvRefMsgs = map[almierrors.AlmiErrorMsg]string{
+	almierrors.ExpandSyntaxErr: "malformed variable reference in: '%s'",
+	almierrors.ExpandUnsetErr:  "variable '%s' is not set: %s",
+	almierrors.ExpandCycleErr:  "variable references form a cycle: %s",
+}
+
+// interpolate writes the value of the reference following a '$' to b, a '$' that doesn't start a reference is kept.
+// line and col are the position of the '$', the problems with the reference are reported as syntax errors there.
+func (p *dotenvParser) interpolate(b *strings.Builder, line, col int) error {
+	var ref string
+	switch {
+	case p.peek() == dotenvRefOpen:
+		p.next()
+		start, depth := p.pos, 0
+		for {
+			if p.eof() || p.peek() == '\n' {
+				return p.errorAt(line, col, dotenvUnterminatedRefMsg)
+			}
+
+			r := p.next()
+			if r == dotenvRef && p.peek() == dotenvRefOpen {
+				depth++
+				p.next()
+			} else if r == refClose {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		ref = string(p.src[start : p.pos-1])
+	case isRefNameRune(p.peek()) && !unicode.IsDigit(p.peek()):
+		start := p.pos
+		p.skip(isRefNameRune)
+		ref = string(p.src[start:p.pos])
+	default:
+		b.WriteRune(dotenvRef)
+		return nil
+	}
+
+	e := &expander{src: sourceChain{p.values, EnvSource{}}, flat: true}
+	e.fail = func(msg almierrors.AlmiErrorMsg, args ...any) error {
+		return &almierrors.SyntaxError{File: p.file, Line: line, Column: col, Msg: fmt.Sprintf(dotenvRefMsgs[msg], args...), Err: msg}
+	}
+	val, err := e.resolve(ref)
+	if err != nil {
+		return err
+	}
+
+	b.WriteString(val)
+	return nil
+}
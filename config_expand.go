@@ -0,0 +1,146 @@
+package almiconfig
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+const (
+	refOpen    = "${"
+	refClose   = '}'
+	refEscape  = "$$"
+	refDefault = ":-"
+	refError   = ":?"
+	refCycle   = " -> "
+
+	refName      = "^[^\\s${}:]+$"
+	refUnsetNote = "a value is required"
+)
+
+var refNameRegexp = regexp.MustCompile(refName)
+
+// expander resolves the ${VAR}, ${VAR:-default} and ${VAR:?error} references of a value against a source,
+// '$$' is an escaped '$'. The values of the referenced variables are expanded as well.
+type expander struct {
+	field string
+	src   Source
+	// resolving is the chain of variables being expanded, a variable referenced again within it is a cycle.
+	resolving []string
+	// flat uses the values of the referenced variables as they are, for sources that are already expanded.
+	flat bool
+	// fail builds the errors of malformed and unset references instead of the Expand templates, args follow
+	// the field in the arguments of msg.
+	fail func(msg almierrors.AlmiErrorMsg, args ...any) error
+}
+
+// errorf builds the error of a reference from msg, one of the Expand templates, for the field.
+func (e *expander) errorf(msg almierrors.AlmiErrorMsg, args ...any) error {
+	if e.fail != nil {
+		return e.fail(msg, args...)
+	}
+
+	return msg.Build(append([]any{e.field}, args...)...)
+}
+
+// expand expands the references of s.
+func (e *expander) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], refEscape):
+			b.WriteByte(refEscape[0])
+			i += len(refEscape)
+		case strings.HasPrefix(s[i:], refOpen):
+			start := i + len(refOpen)
+			end := closingBrace(s, start)
+			if end < 0 {
+				return consts.EMPTY, e.errorf(almierrors.ExpandSyntaxErr, s)
+			}
+
+			val, err := e.resolve(s[start:end])
+			if err != nil {
+				return consts.EMPTY, err
+			}
+
+			b.WriteString(val)
+			i = end + 1
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+
+	return b.String(), nil
+}
+
+// resolve expands a single reference, ref is what is between its braces.
+func (e *expander) resolve(ref string) (string, error) {
+	name, op, word := ref, consts.EMPTY, consts.EMPTY
+	if i := strings.IndexByte(ref, refDefault[0]); i >= 0 {
+		name, op = ref[:i], ref[i:min(i+len(refDefault), len(ref))]
+		word = ref[i+len(op):]
+	}
+
+	if !refNameRegexp.MatchString(name) || (op != consts.EMPTY && op != refDefault && op != refError) {
+		return consts.EMPTY, e.errorf(almierrors.ExpandSyntaxErr, refOpen+ref+string(refClose))
+	}
+
+	raw, ok := e.src.Lookup(name)
+	switch {
+	case ok && (raw != consts.EMPTY || op == consts.EMPTY):
+		if e.flat {
+			return raw, nil
+		}
+
+		if i := slices.Index(e.resolving, name); i >= 0 {
+			cycle := append(slices.Clone(e.resolving[i:]), name)
+			return consts.EMPTY, e.errorf(almierrors.ExpandCycleErr, strings.Join(cycle, refCycle))
+		}
+
+		e.resolving = append(e.resolving, name)
+		defer func() { e.resolving = e.resolving[:len(e.resolving)-1] }()
+		return e.expand(raw)
+	case op == refDefault:
+		return e.expand(word)
+	case op == refError:
+		if word == consts.EMPTY {
+			word = refUnsetNote
+		}
+		return consts.EMPTY, e.errorf(almierrors.ExpandUnsetErr, name, word)
+	}
+
+	return consts.EMPTY, nil
+}
+
+// closingBrace returns the index of the brace closing the reference whose name starts at from,
+// braces of nested references are skipped. It returns -1 when the reference is not closed.
+func closingBrace(s string, from int) int {
+	depth := 0
+	for i := from; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], refEscape):
+			i++
+		case strings.HasPrefix(s[i:], refOpen):
+			depth++
+			i++
+		case s[i] == refClose:
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
+
+// expand expands the references of s against the sources of the field, the field's own key starts the chain
+// of variables being resolved, so a value referring back to its own field is reported as a cycle.
+func (cc *configConstraint) expand(s string) (string, error) {
+	e := &expander{field: cc.FieldName, src: cc.source(), resolving: []string{cc.EnvName}}
+	return e.expand(s)
+}
@@ -0,0 +1,66 @@
+package almiconfig
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+const (
+	// fileSuffix marks the variable holding the path of the file the value of a field is read from,
+	// like POSTGRES_PASSWORD_FILE=/run/secrets/pg for POSTGRES_PASSWORD.
+	fileSuffix = "_FILE"
+
+	defaultFileSizeLimit int64 = 1 << 20
+
+	trailingNewlines = "\r\n"
+)
+
+// readFile reads the value of the field from the file at path, the trailing newlines editors
+// and secret managers leave at the end of the file are trimmed.
+func (cc *configConstraint) readFile(path string) (string, error) {
+	contents, ok, err := readValueFile(path, cc.FileSizeLimit)
+	switch {
+	case err != nil:
+		return consts.EMPTY, almierrors.FileReadErr.Wrap(err, cc.FieldName, cc.key(), path)
+	case !ok:
+		return consts.EMPTY, almierrors.FileTooLargeErr.Build(cc.FieldName, path, cc.key(), fileSizeLimit(cc.FileSizeLimit))
+	}
+
+	return contents, nil
+}
+
+// readValueFile reads the file at path with its trailing newlines trimmed, ok is false when the file
+// is larger than limit bytes.
+func readValueFile(path string, limit int64) (string, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return consts.EMPTY, false, err
+	}
+	defer f.Close()
+
+	limit = fileSizeLimit(limit)
+
+	// one byte more than the limit is read to tell a file of exactly the limit from a larger one
+	contents, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return consts.EMPTY, false, err
+	}
+
+	if int64(len(contents)) > limit {
+		return consts.EMPTY, false, nil
+	}
+
+	return strings.TrimRight(string(contents), trailingNewlines), true, nil
+}
+
+// fileSizeLimit is limit, or the default limit when it is not set.
+func fileSizeLimit(limit int64) int64 {
+	if limit <= 0 {
+		return defaultFileSizeLimit
+	}
+	return limit
+}
@@ -0,0 +1,89 @@
+package almiconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	pgPasswordEnv     = "POSTGRES_PASSWORD"
+	pgPasswordFileEnv = "POSTGRES_PASSWORD_FILE"
+	pgPassword        = "s3cret"
+	pgPasswordFile    = "s3cret\n\n"
+	secretFileName    = "pg"
+	missingFileName   = "missing"
+	largeFileLimit    = int64(4)
+	pgPasswordPathEnv = "PG_PASSWORD_PATH"
+	fallbackPassword  = "fallback"
+	passwordPath      = "Password"
+)
+
+type testFileConfig struct {
+	Password string `almi:"required,env=POSTGRES_PASSWORD"`
+}
+
+type testFileTagConfig struct {
+	Password string `almi:"env=PG_PASSWORD_PATH,file,default=fallback"`
+}
+
+func writeSecretFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), secretFileName)
+	assert.Nil(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestLoader_Load_Successful_FileSuffix(t *testing.T) {
+	path := writeSecretFile(t, pgPasswordFile)
+
+	cfg := testFileConfig{}
+	report, err := NewLoader(WithSources(MapSource{pgPasswordFileEnv: path})).Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, pgPassword, cfg.Password)
+
+	origin, _ := report.Origin(passwordPath)
+	assert.Equal(t, pgPasswordFileEnv, origin.Key)
+}
+
+func TestLoad_Successful_FileSuffixKeyWins(t *testing.T) {
+	path := writeSecretFile(t, pgPasswordFile)
+
+	cfg := testFileConfig{}
+	err := Load(context.Background(), &cfg, MapSource{pgPasswordEnv: pgHost, pgPasswordFileEnv: path})
+	assert.Nil(t, err)
+	assert.Equal(t, pgHost, cfg.Password)
+}
+
+func TestLoad_Successful_FileTag(t *testing.T) {
+	path := writeSecretFile(t, pgPasswordFile)
+
+	cfg := testFileTagConfig{}
+	err := Load(context.Background(), &cfg, MapSource{pgPasswordPathEnv: path})
+	assert.Nil(t, err)
+	assert.Equal(t, pgPassword, cfg.Password)
+
+	err = Load(context.Background(), &cfg, MapSource{})
+	assert.Nil(t, err)
+	assert.Equal(t, fallbackPassword, cfg.Password)
+}
+
+func TestLoad_Fail_FileMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), missingFileName)
+
+	cfg := testFileTagConfig{}
+	err := Load(context.Background(), &cfg, MapSource{pgPasswordPathEnv: path})
+	assert.ErrorIs(t, err, almierrors.ErrFile)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoader_Load_Fail_FileTooLarge(t *testing.T) {
+	path := writeSecretFile(t, pgPasswordFile)
+
+	cfg := testFileConfig{}
+	_, err := NewLoader(WithSources(MapSource{pgPasswordFileEnv: path}), WithFileSizeLimit(largeFileLimit)).Load(context.Background(), &cfg)
+	assert.ErrorIs(t, err, almierrors.FileTooLargeErr)
+}
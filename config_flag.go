@@ -0,0 +1,153 @@
+package almiconfig
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+const (
+	flagSourceName = "flags"
+
+	flagSep    = "-"
+	flagAssign = "="
+)
+
+// flagValue is the flag.Value of a single config field, it remembers whether the flag was set on the command line,
+// so flags that aren't given fall through to the sources below them.
+type flagValue struct {
+	val    string
+	set    bool
+	isBool bool
+}
+
+func (fv *flagValue) String() string {
+	if fv == nil {
+		return consts.EMPTY
+	}
+	return fv.val
+}
+
+func (fv *flagValue) Set(s string) error {
+	fv.val = s
+	fv.set = true
+	return nil
+}
+
+// IsBoolFlag lets bool fields be set with a bare -name.
+func (fv *flagValue) IsBoolFlag() bool {
+	return fv.isBool
+}
+
+// FlagSource reads the values of the command-line flags registered by Loader.RegisterFlags.
+// Only the flags set on the command line are present, fields are looked up by their path
+// and the flags by their name.
+type FlagSource struct {
+	byPath map[string]*flagValue
+	byName map[string]*flagValue
+}
+
+func (*FlagSource) Name() string {
+	return flagSourceName
+}
+
+// Lookup looks the flag up by its name, without the leading '-'.
+func (fs *FlagSource) Lookup(name string) (string, bool) {
+	return lookupFlag(fs.byName[name])
+}
+
+func (fs *FlagSource) LookupPath(path []string) (any, bool) {
+	return lookupFlag(fs.byPath[strings.Join(path, pathSep)])
+}
+
+func lookupFlag(fv *flagValue) (string, bool) {
+	if fv == nil || !fv.set {
+		return consts.EMPTY, false
+	}
+
+	return fv.val, true
+}
+
+// RegisterFlags defines a flag on fs for every field of cfg, which must be a pointer to a struct, and adds them
+// to the loader as its highest-precedence source. The flag is named after the 'flag=' constraint of the field,
+// or its env name in lower case with '-' instead of '_', like -postgres-port for POSTGRES_PORT.
+// Its usage text is the 'desc=' constraint and its default the 'default=' constraint.
+// fs has to be parsed before Load is called.
+func (l *Loader) RegisterFlags(fs *flag.FlagSet, cfg any) (*FlagSource, error) {
+	t := reflect.TypeOf(cfg)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return nil, almierrors.InvalidConfigErr.Build(t)
+	}
+
+	src := &FlagSource{byPath: map[string]*flagValue{}, byName: map[string]*flagValue{}}
+	if err := l.registerFlags(fs, src, reflect.New(t.Elem()).Elem(), scope{}); err != nil {
+		return nil, err
+	}
+
+	l.sources = append(sourceChain{src}, l.sources...)
+
+	return src, nil
+}
+
+// registerFlags walks cfg the same way Load does, so the flags are named after the same env names and paths.
+func (l *Loader) registerFlags(fs *flag.FlagSet, src *FlagSource, cfg reflect.Value, sc scope) error {
+	for i := 0; i < cfg.NumField(); i++ {
+		val := getConfigValueByIndex(cfg, i, sc.path)
+		if !val.Field.IsExported() && (!val.Field.Anonymous || val.isPointer()) {
+			continue
+		}
+
+		cc := newConfigConstraint(val)
+		if err := cc.parseConstraints(val.Constraints); err != nil {
+			return almierrors.NewFieldError(val.Path, cc.EnvName, consts.EMPTY, err)
+		}
+
+		if val.isStruct() {
+			if err := l.registerFlags(fs, src, reflect.New(val.valueType()).Elem(), sc.nested(val, cc)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if cc.EnvName == consts.EMPTY && l.naming != nil {
+			cc.EnvName = sc.envName(val, l.naming)
+		}
+
+		if cc.EnvName != consts.EMPTY {
+			cc.withPrefix(sc.envPrefix)
+		}
+
+		path := sc.docPath(val, cc)
+		name := cc.flagName(path)
+		if name == consts.EMPTY || strings.HasPrefix(name, flagSep) || strings.Contains(name, flagAssign) {
+			return almierrors.NewFieldError(val.Path, cc.EnvName, consts.EMPTY, almierrors.FlagNameInvalidErr.Build(val.Path, name))
+		}
+
+		if fs.Lookup(name) != nil {
+			return almierrors.NewFieldError(val.Path, cc.EnvName, consts.EMPTY, almierrors.FlagRedefinedErr.Build(val.Path, name))
+		}
+
+		fv := &flagValue{val: cc.Default, isBool: val.valueType().Kind() == reflect.Bool}
+		fs.Var(fv, name, cc.Desc)
+		src.byPath[strings.Join(path, pathSep)] = fv
+		src.byName[name] = fv
+	}
+
+	return nil
+}
+
+// flagName is the 'flag=' constraint of the field, or its env name in kebab case,
+// fields without an env name are named after their document path.
+func (cc *configConstraint) flagName(path []string) string {
+	switch {
+	case cc.Flag != consts.EMPTY:
+		return cc.Flag
+	case cc.EnvName != consts.EMPTY:
+		return strings.ToLower(strings.ReplaceAll(cc.EnvName, "_", flagSep))
+	default:
+		return Kebab(path)
+	}
+}
@@ -0,0 +1,112 @@
+package almiconfig
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"testing"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	flagSetName       = "app"
+	pgHostFlag        = "pg-host"
+	pgPortFlag        = "pg-port"
+	flagPgPort        = "6543"
+	flagPgPortV       = int(6543)
+	debugFlag         = "debug"
+	timeoutFlag       = "timeout"
+	flagUsagePgPort   = "port of the database, like 5432"
+	flagUsageDefault  = "(default 5432)"
+	envPgHost         = "env-postgres"
+	flagTimeout       = "3"
+	flagTimeoutDefV   = int(10)
+	flagRedefinedName = "dup"
+)
+
+type testFlagDBConfig struct {
+	Host string `almi:"required,env=HOST"`
+	Port int    `almi:"env=PORT,default=5432,desc=port of the database, like 5432"`
+}
+
+type testFlagConfig struct {
+	Postgres testFlagDBConfig `almi:"prefix=PG_"`
+	Debug    bool             `almi:"env=APP_DEBUG,flag=debug"`
+	Timeout  int              `almi:"env=TIMEOUT,default=10"`
+}
+
+func TestLoader_RegisterFlags_Successful(t *testing.T) {
+	fs := flag.NewFlagSet(flagSetName, flag.ContinueOnError)
+	l := NewLoader(WithSources(MapSource{pgHostEnv: envPgHost, pgPortEnv: pgPort}))
+
+	cfg := testFlagConfig{}
+	_, err := l.RegisterFlags(fs, &cfg)
+	assert.Nil(t, err)
+	assert.NotNil(t, fs.Lookup(pgHostFlag))
+	assert.NotNil(t, fs.Lookup(debugFlag))
+
+	assert.Nil(t, fs.Parse([]string{"-" + pgPortFlag, flagPgPort, "-" + debugFlag}))
+
+	report, err := l.Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, envPgHost, cfg.Postgres.Host)
+	assert.Equal(t, flagPgPortV, cfg.Postgres.Port)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, flagTimeoutDefV, cfg.Timeout)
+
+	origin, _ := report.Origin(pgPortPath)
+	assert.Equal(t, flagSourceName, origin.Source)
+}
+
+func TestLoader_RegisterFlags_Successful_Usage(t *testing.T) {
+	fs := flag.NewFlagSet(flagSetName, flag.ContinueOnError)
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+
+	_, err := NewLoader().RegisterFlags(fs, &testFlagConfig{})
+	assert.Nil(t, err)
+
+	fs.PrintDefaults()
+	assert.Contains(t, usage.String(), flagUsagePgPort)
+	assert.Contains(t, usage.String(), flagUsageDefault)
+}
+
+func TestFlagSource_Lookup(t *testing.T) {
+	fs := flag.NewFlagSet(flagSetName, flag.ContinueOnError)
+	src, err := NewLoader().RegisterFlags(fs, &testFlagConfig{})
+	assert.Nil(t, err)
+
+	_, ok := src.Lookup(timeoutFlag)
+	assert.False(t, ok)
+
+	assert.Nil(t, fs.Parse([]string{"-" + timeoutFlag, flagTimeout}))
+	val, ok := src.Lookup(timeoutFlag)
+	assert.True(t, ok)
+	assert.Equal(t, flagTimeout, val)
+}
+
+func TestLoader_RegisterFlags_Fail(t *testing.T) {
+	fs := flag.NewFlagSet(flagSetName, flag.ContinueOnError)
+	fs.Bool(debugFlag, false, flagRedefinedName)
+
+	_, err := NewLoader().RegisterFlags(fs, &testFlagConfig{})
+	assert.ErrorIs(t, err, almierrors.FlagRedefinedErr)
+
+	_, err = NewLoader().RegisterFlags(fs, testFlagConfig{})
+	assert.ErrorIs(t, err, almierrors.InvalidConfigErr)
+
+	_, err = NewLoader().RegisterFlags(flag.NewFlagSet(flagSetName, flag.ContinueOnError), &struct {
+		Port int `almi:"env=PORT,flag=-port"`
+	}{})
+	assert.ErrorIs(t, err, almierrors.FlagNameInvalidErr)
+}
+
+func TestLoad_Fail_DescBeforeRequired(t *testing.T) {
+	cfg := struct {
+		Port int `almi:"env=PORT,desc=port of the database, like 5432,required"`
+	}{}
+	err := Load(context.Background(), &cfg, MapSource{})
+	assert.ErrorIs(t, err, almierrors.FieldRequiredErr)
+}
@@ -0,0 +1,130 @@
+package almiconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+const (
+	iniSourceName = "ini"
+
+	iniSectionOpen  = "["
+	iniSectionClose = "]"
+	iniAssign       = "="
+	iniComments     = ";#"
+	iniQuotes       = "\"'"
+
+	iniUnterminatedSectionMsg = "unterminated section header"
+	iniEmptySectionMsg        = "empty section name in '%s'"
+	iniExpectedAssignMsg      = "expected '=' after key '%s'"
+	iniExpectedKeyMsg         = "expected a key before '='"
+	iniKeyIsSectionMsg        = "'%s' is both a value and a section"
+)
+
+// ReadINI parses the INI file at path.
+func ReadINI(path string) (*DocumentSource, error) {
+	return readDocument(path, parseINI)
+}
+
+// ParseINI parses an INI document read from r. Every [section] is looked up as a nested struct,
+// dotted sections like [postgres.replica] as structs nested several levels, keys above the first section
+// belong to the top level. Lines starting with ';' or '#' are comments, values can be quoted
+// and unquoted values end at a ';' or '#' comment following whitespace. A key set more than once keeps its last value.
+// Syntax errors are reported as an *almierrors.SyntaxError with the line of the problem.
+func ParseINI(r io.Reader) (*DocumentSource, error) {
+	return parseDocument(iniSourceName, r, parseINI)
+}
+
+func parseINI(file string, data []byte) (*DocumentSource, error) {
+	doc := map[string]any{}
+	section := doc
+
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte(dotenvBOM))))
+	for line := 1; scanner.Scan(); line++ {
+		raw := scanner.Text()
+		text := strings.TrimSpace(raw)
+		col := strings.Index(raw, text) + 1
+
+		switch {
+		case text == consts.EMPTY || strings.ContainsAny(text[:1], iniComments):
+			continue
+		case strings.HasPrefix(text, iniSectionOpen):
+			name, _, ok := strings.Cut(text[1:], iniSectionClose)
+			if !ok {
+				return nil, &almierrors.SyntaxError{File: file, Line: line, Column: col, Msg: iniUnterminatedSectionMsg}
+			}
+
+			var err error
+			if section, err = iniSection(doc, name); err != nil {
+				return nil, &almierrors.SyntaxError{File: file, Line: line, Column: col, Msg: err.Error()}
+			}
+		default:
+			key, val, ok := strings.Cut(text, iniAssign)
+			key = strings.TrimSpace(key)
+			switch {
+			case !ok:
+				return nil, &almierrors.SyntaxError{File: file, Line: line, Column: col + len(text), Msg: fmt.Sprintf(iniExpectedAssignMsg, key)}
+			case key == consts.EMPTY:
+				return nil, &almierrors.SyntaxError{File: file, Line: line, Column: col, Msg: iniExpectedKeyMsg}
+			}
+
+			if _, isSection := section[key].(map[string]any); isSection {
+				return nil, &almierrors.SyntaxError{File: file, Line: line, Column: col, Msg: fmt.Sprintf(iniKeyIsSectionMsg, key)}
+			}
+			section[key] = iniValue(strings.TrimSpace(val))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return newDocumentSource(iniSourceName, file, doc)
+}
+
+// iniSection returns the table of the dotted section name, creating the tables on its path.
+func iniSection(doc map[string]any, name string) (map[string]any, error) {
+	section := doc
+	for _, segment := range strings.Split(name, pathSep) {
+		segment = strings.TrimSpace(segment)
+		if segment == consts.EMPTY {
+			return nil, fmt.Errorf(iniEmptySectionMsg, name)
+		}
+
+		switch next := section[segment].(type) {
+		case map[string]any:
+			section = next
+		case nil:
+			table := map[string]any{}
+			section[segment] = table
+			section = table
+		default:
+			return nil, fmt.Errorf(iniKeyIsSectionMsg, segment)
+		}
+	}
+
+	return section, nil
+}
+
+// iniValue unquotes a quoted value, or cuts the trailing comment off an unquoted one.
+func iniValue(val string) string {
+	if val != consts.EMPTY && strings.ContainsAny(val[:1], iniQuotes) {
+		if end := strings.IndexByte(val[1:], val[0]); end >= 0 {
+			return val[1 : end+1]
+		}
+	}
+
+	for i := 0; i < len(val); i++ {
+		if strings.ContainsAny(val[i:i+1], iniComments) && (i == 0 || isBlank(rune(val[i-1]))) {
+			return strings.TrimSpace(val[:i])
+		}
+	}
+
+	return val
+}
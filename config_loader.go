@@ -0,0 +1,379 @@
+package almiconfig
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+// Loader fills configs from an ordered list of sources and reports where every value came from.
+// The sources are layered by precedence: for every field they are tried in order and the first one
+// that has the key wins, the field's 'default=' is the lowest layer below all of them.
+type Loader struct {
+	sources  sourceChain
+	failFast bool
+	naming   NamingStrategy
+	logger   Logger
+	expand   bool
+	fileSize int64
+}
+
+const deprecatedKeyNotice = "almiconfig: field '%s' was read from deprecated key '%s', use '%s' instead"
+
+// Logger receives the notices of a Loader, like the use of a deprecated key, *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+type Option func(*Loader)
+
+// WithSources appends sources to the loader, sources added first take precedence over the ones added after them.
+func WithSources(sources ...Source) Option {
+	return func(l *Loader) {
+		l.sources = append(l.sources, sources...)
+	}
+}
+
+// FailFast makes the loader stop at the first invalid field, by default every field is loaded
+// and all the problems are returned together in an almierrors.Errors.
+func FailFast() Option {
+	return func(l *Loader) {
+		l.failFast = true
+	}
+}
+
+// WithNaming derives the key of every field without an 'env=' constraint from its field path through strategy,
+// an explicit 'env=' always takes precedence over the derived key.
+func WithNaming(strategy NamingStrategy) Option {
+	return func(l *Loader) {
+		l.naming = strategy
+	}
+}
+
+// WithLogger sets the logger a deprecation notice is written to whenever a field is read from one of its
+// legacy 'env=NEW|OLD' names.
+func WithLogger(logger Logger) Option {
+	return func(l *Loader) {
+		l.logger = logger
+	}
+}
+
+// WithExpansion expands the ${VAR} references of every field, as if all of them had the 'expand' constraint.
+func WithExpansion() Option {
+	return func(l *Loader) {
+		l.expand = true
+	}
+}
+
+// WithFileSizeLimit caps the size of the files values are read from, through the 'file' constraint
+// or a KEY_FILE variable, at limit bytes instead of the default of 1 MiB.
+func WithFileSizeLimit(limit int64) Option {
+	return func(l *Loader) {
+		l.fileSize = limit
+	}
+}
+
+// NewLoader creates a Loader, the environment is used as the only source when no sources are given.
+func NewLoader(opts ...Option) *Loader {
+	l := &Loader{fileSize: defaultFileSizeLimit}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if len(l.sources) == 0 {
+		l.sources = sourceChain{EnvSource{}}
+	}
+
+	return l
+}
+
+// Load fills cfg, which must be a non-nil pointer to a struct, and returns the provenance of every field.
+// cfg is left untouched when loading fails.
+func (l *Loader) Load(ctx context.Context, cfg any) (*Report, error) {
+	ptr := reflect.ValueOf(cfg)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return nil, almierrors.InvalidConfigErr.Build(reflect.TypeOf(cfg))
+	}
+
+	config := reflect.New(ptr.Elem().Type()).Elem()
+	config.Set(ptr.Elem())
+
+	ls := &loadState{ctx: ctx, sources: l.sources, naming: l.naming, logger: l.logger, expand: l.expand, fileSize: l.fileSize, report: &Report{}, failFast: l.failFast}
+	if _, err := ls.validateStruct(config, scope{}); err != nil {
+		return nil, err
+	}
+
+	if len(ls.errs) != 0 {
+		return nil, ls.errs
+	}
+
+	ptr.Elem().Set(config)
+
+	return ls.report, nil
+}
+
+// Load fills cfg from sources, which are tried in order for every field, the first source that has a key wins.
+// The environment is used when no sources are given. cfg is left untouched when loading fails.
+func Load[T any](ctx context.Context, cfg *T, sources ...Source) error {
+	_, err := NewLoader(WithSources(sources...)).Load(ctx, cfg)
+	return err
+}
+
+// loadState holds everything needed during a single Loader.Load call.
+type loadState struct {
+	ctx      context.Context
+	sources  sourceChain
+	naming   NamingStrategy
+	logger   Logger
+	expand   bool
+	fileSize int64
+	report   *Report
+	failFast bool
+	errs     almierrors.Errors
+}
+
+// scope is where a struct sits in the config while it is walked.
+type scope struct {
+	// path is the dotted field path of the struct.
+	path string
+	// envPrefix is prepended to every env name found below the struct.
+	envPrefix string
+	// names are the field names the naming strategy derives keys from, they start over below a struct with a prefix.
+	names []string
+	// keys is the document path of the struct, the names of its fields or their 'key=' overrides.
+	keys []string
+}
+
+// nested is the scope of the fields of the struct field val.
+// Embedded structs are promoted, so they don't add a segment to the field path nor to the derived names.
+func (sc scope) nested(val *configValue, cc *configConstraint) scope {
+	nested := scope{path: val.Path, envPrefix: sc.envPrefix + cc.Prefix, names: sc.names, keys: sc.keys}
+	if val.Field.Anonymous {
+		nested.path = sc.path
+	} else {
+		nested.names = append(slices.Clip(sc.names), val.Field.Name)
+		nested.keys = sc.docPath(val, cc)
+	}
+
+	if cc.Prefix != consts.EMPTY {
+		nested.names = nil
+	}
+
+	return nested
+}
+
+// docPath is the document path of the field val, its 'key=' can hold several dotted segments.
+func (sc scope) docPath(val *configValue, cc *configConstraint) []string {
+	if cc.Key != consts.EMPTY {
+		return append(slices.Clip(sc.keys), strings.Split(cc.Key, pathSep)...)
+	}
+
+	return append(slices.Clip(sc.keys), val.Field.Name)
+}
+
+// envName derives the key of the field val through strategy.
+func (sc scope) envName(val *configValue, strategy NamingStrategy) string {
+	return strategy(append(slices.Clip(sc.names), val.Field.Name))
+}
+
+// fieldError records err for the field, it only returns an error when loading should stop right away.
+func (ls *loadState) fieldError(val *configValue, cc *configConstraint, err error) error {
+	fe := almierrors.NewFieldError(val.Path, cc.key(), cc.Value, err)
+	if ls.failFast {
+		return fe
+	}
+
+	ls.errs = append(ls.errs, fe)
+	return nil
+}
+
+// presence tells whether a field, or any of the fields below a struct, got its value from a source or from a default.
+type presence uint8
+
+const (
+	absent presence = iota
+	defaulted
+	present
+)
+
+// validateStruct walks the fields of cfg, descending into nested and embedded structs.
+// It reports the highest presence of the fields below cfg.
+func (ls *loadState) validateStruct(cfg reflect.Value, sc scope) (presence, error) {
+	structPresence := absent
+	for i := 0; i < cfg.NumField(); i++ {
+		if err := ls.ctx.Err(); err != nil {
+			return absent, err
+		}
+
+		// fields of unexported embedded structs are promoted and can be set, unexported embedded pointers can't
+		val := getConfigValueByIndex(cfg, i, sc.path)
+		if !val.Field.IsExported() && (!val.Field.Anonymous || val.isPointer()) {
+			continue
+		}
+
+		fieldPresence, err := ls.validateField(cfg, val, sc)
+		if err != nil {
+			return absent, err
+		}
+
+		structPresence = max(structPresence, fieldPresence)
+	}
+
+	return structPresence, nil
+}
+
+// validatePointerStruct walks a new struct for a pointer to struct field, and only points the field to it
+// when any of its fields is set. The problems with the fields of a struct that is left nil are dropped,
+// so its required fields are only enforced when it is configured at all. Mistakes in the tags of its fields
+// don't depend on it being configured and are always reported.
+func (ls *loadState) validatePointerStruct(val *configValue, sc scope) (presence, error) {
+	nested := reflect.New(val.valueType()).Elem()
+
+	sub := &loadState{ctx: ls.ctx, sources: ls.sources, naming: ls.naming, logger: ls.logger, expand: ls.expand, fileSize: ls.fileSize, report: &Report{}}
+	structPresence, err := sub.validateStruct(nested, sc)
+	if err != nil {
+		return absent, err
+	}
+
+	configured := structPresence != absent
+	setPointer(val.Value, nested, configured)
+	if configured {
+		ls.report.Fields = append(ls.report.Fields, sub.report.Fields...)
+	}
+
+	for _, err := range sub.errs {
+		if !configured && !errors.Is(err, almierrors.ErrInvalidTag) && !errors.Is(err, almierrors.ErrTypeMismatch) {
+			continue
+		}
+
+		if ls.failFast {
+			return absent, err
+		}
+		ls.errs = append(ls.errs, err)
+	}
+
+	return structPresence, nil
+}
+
+// validateField loads a single field of cfg, problems with the field are recorded through fieldError.
+func (ls *loadState) validateField(cfg reflect.Value, val *configValue, sc scope) (presence, error) {
+	cfgConstraint := newConfigConstraint(val)
+	cfgConstraint.Source = ls.sources
+	if err := cfgConstraint.parseConstraints(val.Constraints); err != nil {
+		return absent, ls.fieldError(val, cfgConstraint, err)
+	}
+
+	if val.isStruct() {
+		if err := cfgConstraint.checkStructConstraints(val); err != nil {
+			return absent, ls.fieldError(val, cfgConstraint, err)
+		}
+
+		nested := sc.nested(val, cfgConstraint)
+
+		var (
+			structPresence presence
+			err            error
+		)
+		if val.isPointer() {
+			structPresence, err = ls.validatePointerStruct(val, nested)
+		} else {
+			structPresence, err = ls.validateStruct(val.Value, nested)
+		}
+		if err != nil {
+			return absent, err
+		}
+
+		if cfgConstraint.Required && structPresence != present {
+			return absent, ls.fieldError(val, cfgConstraint, almierrors.StructRequiredErr.Build(val.Path))
+		}
+
+		return structPresence, nil
+	}
+
+	if cfgConstraint.Prefix != consts.EMPTY {
+		return absent, ls.fieldError(val, cfgConstraint, almierrors.PrefixOnNonStructErr.Build(val.Path))
+	}
+
+	cfgConstraint.Path = sc.docPath(val, cfgConstraint)
+	cfgConstraint.Expand = cfgConstraint.Expand || ls.expand
+	cfgConstraint.FileSizeLimit = ls.fileSize
+
+	if cfgConstraint.EnvName == consts.EMPTY && ls.naming != nil {
+		cfgConstraint.EnvName = sc.envName(val, ls.naming)
+	}
+
+	if cfgConstraint.EnvName != consts.EMPTY {
+		cfgConstraint.withPrefix(sc.envPrefix)
+	}
+
+	if err := cfgConstraint.checkConstraints(val); err != nil {
+		return absent, ls.fieldError(val, cfgConstraint, err)
+	}
+
+	if cfgConstraint.Preferred != consts.EMPTY && ls.logger != nil {
+		ls.logger.Printf(deprecatedKeyNotice, val.Path, cfgConstraint.EnvName, cfgConstraint.Preferred)
+	}
+
+	fieldPresence := absent
+	switch {
+	case cfgConstraint.DefaultUsed:
+		fieldPresence = defaulted
+	case cfgConstraint.Present:
+		fieldPresence = present
+	}
+
+	// pointer fields are left nil when there is nothing to point to
+	if val.isPointer() && fieldPresence == absent {
+		if err := cfgConstraint.checkBounds(reflect.Value{}); err != nil {
+			return absent, ls.fieldError(val, cfgConstraint, err)
+		}
+
+		setPointer(val.Value, reflect.Value{}, false)
+		ls.report.record(val, cfgConstraint)
+		return absent, nil
+	}
+
+	if err := cfgConstraint.resolveType(cfg, val); err != nil {
+		return absent, ls.fieldError(val, cfgConstraint, err)
+	}
+
+	envVar, err := cfgConstraint.findType()
+	if err != nil {
+		var (
+			rangeErr *almierrors.RangeError
+			almiErr  *almierrors.AlmiError
+		)
+		switch {
+		case errors.As(err, &rangeErr), errors.As(err, &almiErr):
+			// already describes the problem with the field
+		case cfgConstraint.DefaultUsed:
+			err = almierrors.FailedToConvertDefaultTypeErr.Wrap(err, cfgConstraint.Default, val.Path, cfgConstraint.typeName())
+		default:
+			err = almierrors.FailedToConvertTypeErr.Wrap(err, cfgConstraint.key(), cfgConstraint.typeName())
+		}
+		return absent, ls.fieldError(val, cfgConstraint, err)
+	}
+
+	// the bounds of fields that aren't set are not enforced
+	bounded := reflect.ValueOf(envVar)
+	if fieldPresence == absent {
+		bounded = reflect.Value{}
+	}
+
+	if err := cfgConstraint.checkBounds(bounded); err != nil {
+		return absent, ls.fieldError(val, cfgConstraint, err)
+	}
+
+	if err := setFieldValue(envVar, cfg, val, cfgConstraint); err != nil {
+		return absent, ls.fieldError(val, cfgConstraint, err)
+	}
+
+	ls.report.record(val, cfgConstraint)
+
+	return fieldPresence, nil
+}
@@ -0,0 +1,219 @@
+package almiconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	flagsLayer = "flags"
+	fileLayer  = "file"
+
+	layeredPgPort      = "5433"
+	layeredPgPortV     = int(5433)
+	layeredPgHost      = "staging-postgres"
+	layeredReport      = "Postgres.Host: PG_HOST from file\nPostgres.Port: PG_PORT from flags\nAccessLifetime: ACCESS_LIFETIME from default"
+	pgPortPath         = "Postgres.Port"
+	accessLifetimePath = "AccessLifetime"
+	accessSecretPath   = "AccessSecret"
+	unsetFieldPath     = "RefreshSecret"
+	unknownFieldPath   = "Unknown"
+	unsetFieldOrigin   = "RefreshSecret: REFRESH_SECRET from unset"
+	unnamedSourceName  = "almiconfig.testUnnamedSource"
+	postgresPortEnv    = "POSTGRES_PORT"
+	legacyPgPortEnv    = "PGPORT"
+	aliasedPortPath    = "Port"
+	aliasedPortOrigin  = "Port: PGPORT (deprecated, use POSTGRES_PORT) from map"
+	deprecatedNotice   = "almiconfig: field 'Port' was read from deprecated key 'PGPORT', use 'POSTGRES_PORT' instead"
+	manyErrors         = "AlmiConfigError: Field: 'AccessSecret', is required (key: 'ACCESS_SECRET')\n" +
+		"AlmiConfigError: failed to convert type of 'ACCESS_LIFETIME' to int from string (key: 'ACCESS_LIFETIME')\n" +
+		"AlmiConfigError: Field: 'RefreshSecret', is required (key: 'REFRESH_SECRET')"
+)
+
+type testConfigManyErrors struct {
+	AccessSecret   string `almi:"required,env=ACCESS_SECRET"`
+	AccessLifetime int    `almi:"env=ACCESS_LIFETIME,type=int"`
+	RefreshSecret  string `almi:"required,env=REFRESH_SECRET"`
+}
+
+type testUnnamedSource map[string]string
+
+func (s testUnnamedSource) Lookup(key string) (string, bool) {
+	val, ok := s[key]
+	return val, ok
+}
+
+type testAliasedConfig struct {
+	Port int `almi:"required,env=POSTGRES_PORT|PGPORT"`
+}
+
+type testLogger []string
+
+func (tl *testLogger) Printf(format string, v ...any) {
+	*tl = append(*tl, fmt.Sprintf(format, v...))
+}
+
+type testLayeredConfig struct {
+	Postgres       testDBConfig `almi:"prefix=PG_"`
+	AccessLifetime int          `almi:"env=ACCESS_LIFETIME,type=int,default=10"`
+}
+
+func TestLoader_Load_Successful_LayersAndReport(t *testing.T) {
+	l := NewLoader(WithSources(
+		Named(flagsLayer, MapSource{pgPortEnv: layeredPgPort}),
+		Named(fileLayer, MapSource{pgHostEnv: layeredPgHost, pgPortEnv: pgPort}),
+	))
+
+	cfg := testLayeredConfig{}
+	report, err := l.Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, layeredPgHost, cfg.Postgres.Host)
+	assert.Equal(t, layeredPgPortV, cfg.Postgres.Port)
+	assert.Equal(t, accessLifetimeDefaultValue, cfg.AccessLifetime)
+
+	origin, ok := report.Origin(pgPortPath)
+	assert.True(t, ok)
+	assert.Equal(t, FieldOrigin{Path: pgPortPath, Key: pgPortEnv, Source: flagsLayer}, origin)
+	assert.Equal(t, layeredReport, report.String())
+}
+
+func TestLoader_Load_Successful_UnsetFieldOrigin(t *testing.T) {
+	cfg := testConfig{}
+	report, err := NewLoader(WithSources(MapSource{accessSecretEnv: accessSecret, kafkaBrokersEnv: kafkaBrokers})).Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+
+	origin, ok := report.Origin(unsetFieldPath)
+	assert.True(t, ok)
+	assert.Equal(t, unsetFieldOrigin, origin.String())
+
+	_, ok = report.Origin(unknownFieldPath)
+	assert.False(t, ok)
+}
+
+func TestLoader_Load_Successful_UnnamedSourceUsesTypeName(t *testing.T) {
+	cfg := testConfig{}
+	report, err := NewLoader(WithSources(testUnnamedSource{accessSecretEnv: accessSecret, kafkaBrokersEnv: kafkaBrokers})).Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+
+	origin, _ := report.Origin(accessSecretPath)
+	assert.Equal(t, unnamedSourceName, origin.Source)
+}
+
+func TestLoader_Load_Fail_NotAStructPointer(t *testing.T) {
+	cfg := testConfig{}
+	report, err := NewLoader().Load(context.Background(), cfg)
+	assert.Nil(t, report)
+	assert.NotNil(t, err)
+
+	var nilCfg *testConfig
+	report, err = NewLoader().Load(context.Background(), nilCfg)
+	assert.Nil(t, report)
+	assert.NotNil(t, err)
+}
+
+func TestLoader_Load_Fail_AggregatesAllErrors(t *testing.T) {
+	cfg := testConfigManyErrors{}
+	report, err := NewLoader(WithSources(MapSource{accessLifetimeEnv: badVal})).Load(context.Background(), &cfg)
+	assert.Nil(t, report)
+	assert.EqualError(t, err, manyErrors)
+
+	var errs almierrors.Errors
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 3)
+
+	var fieldErr *almierrors.FieldError
+	assert.ErrorAs(t, errs[1], &fieldErr)
+	assert.Equal(t, accessLifetimePath, fieldErr.Path)
+	assert.Equal(t, accessLifetimeEnv, fieldErr.Key)
+	assert.Equal(t, badVal, fieldErr.Value)
+}
+
+func TestLoader_Load_Fail_AggregatedErrorsWorkWithErrorsJoin(t *testing.T) {
+	cfg := testConfigManyErrors{}
+	_, err := NewLoader(WithSources(MapSource{})).Load(context.Background(), &cfg)
+
+	joined := errors.Join(err, context.Canceled)
+	var fieldErr *almierrors.FieldError
+	assert.ErrorAs(t, joined, &fieldErr)
+	assert.Equal(t, accessSecretPath, fieldErr.Path)
+}
+
+func TestLoader_Load_Fail_FailFast(t *testing.T) {
+	cfg := testConfigManyErrors{}
+	_, err := NewLoader(FailFast(), WithSources(MapSource{})).Load(context.Background(), &cfg)
+
+	var errs almierrors.Errors
+	assert.False(t, errors.As(err, &errs))
+
+	var fieldErr *almierrors.FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, accessSecretPath, fieldErr.Path)
+}
+
+func TestLoader_Load_Fail_ErrorsAreInspectable(t *testing.T) {
+	cfg := testConfigManyErrors{}
+	_, err := NewLoader(WithSources(MapSource{accessLifetimeEnv: badVal})).Load(context.Background(), &cfg)
+
+	assert.ErrorIs(t, err, almierrors.ErrRequired)
+	assert.ErrorIs(t, err, almierrors.ErrConversion)
+	assert.ErrorIs(t, err, almierrors.FieldRequiredErr)
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.NotErrorIs(t, err, almierrors.ErrOutOfRange)
+
+	var numErr *strconv.NumError
+	assert.ErrorAs(t, err, &numErr)
+	assert.Equal(t, badVal, numErr.Num)
+}
+
+func TestLoader_Load_Successful_EnvAliases(t *testing.T) {
+	logger := &testLogger{}
+
+	cfg := testAliasedConfig{}
+	report, err := NewLoader(WithSources(MapSource{legacyPgPortEnv: pgPort}), WithLogger(logger)).Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, pgPortValue, cfg.Port)
+	assert.Equal(t, []string{deprecatedNotice}, []string(*logger))
+
+	origin, _ := report.Origin(aliasedPortPath)
+	assert.Equal(t, legacyPgPortEnv, origin.Key)
+	assert.Equal(t, postgresPortEnv, origin.Preferred)
+	assert.Equal(t, aliasedPortOrigin, origin.String())
+}
+
+func TestLoader_Load_Successful_EnvAliasesFirstNameWins(t *testing.T) {
+	logger := &testLogger{}
+
+	cfg := testAliasedConfig{}
+	src := MapSource{postgresPortEnv: pgPort, legacyPgPortEnv: layeredPgPort}
+	report, err := NewLoader(WithSources(src), WithLogger(logger)).Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, pgPortValue, cfg.Port)
+	assert.Empty(t, *logger)
+
+	origin, _ := report.Origin(aliasedPortPath)
+	assert.Equal(t, FieldOrigin{Path: aliasedPortPath, Key: postgresPortEnv, Source: mapSourceName}, origin)
+}
+
+func TestLoader_Load_Fail_EnvAliasesRequired(t *testing.T) {
+	cfg := testAliasedConfig{}
+	_, err := NewLoader(WithSources(MapSource{})).Load(context.Background(), &cfg)
+	assert.ErrorIs(t, err, almierrors.FieldRequiredErr)
+
+	var fieldErr *almierrors.FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, postgresPortEnv, fieldErr.Key)
+}
+
+func TestLoader_Load_Fail_EmptyEnvAlias(t *testing.T) {
+	cfg := struct {
+		Port int `almi:"env=POSTGRES_PORT|"`
+	}{}
+	_, err := NewLoader(WithSources(MapSource{})).Load(context.Background(), &cfg)
+	assert.ErrorIs(t, err, almierrors.EnvAliasEmptyErr)
+}
@@ -0,0 +1,60 @@
+package almiconfig
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives the key of a field that has no 'env=' constraint from its field path,
+// path holds the Go field names from the nearest struct with a 'prefix=' down to the field itself.
+type NamingStrategy func(path []string) string
+
+// ScreamingSnake derives keys like POSTGRES_MAX_IDLE_CONNS from Postgres.MaxIdleConns.
+func ScreamingSnake(path []string) string {
+	return joinWords(path, "_", "_", strings.ToUpper)
+}
+
+// Kebab derives keys like postgres-max-idle-conns from Postgres.MaxIdleConns.
+func Kebab(path []string) string {
+	return joinWords(path, "-", "-", strings.ToLower)
+}
+
+// Dotted derives keys like postgres.max_idle_conns from Postgres.MaxIdleConns.
+func Dotted(path []string) string {
+	return joinWords(path, ".", "_", strings.ToLower)
+}
+
+// joinWords splits every segment of path into words, the segments are joined by segSep and their words by wordSep.
+func joinWords(path []string, segSep, wordSep string, fold func(string) string) string {
+	segments := make([]string, 0, len(path))
+	for _, segment := range path {
+		segments = append(segments, fold(strings.Join(splitWords(segment), wordSep)))
+	}
+
+	return strings.Join(segments, segSep)
+}
+
+// splitWords splits a Go identifier into its words, runs of capitals are kept together as acronyms,
+// so HTTPServerURL becomes HTTP, Server and URL. Underscores separate words as well.
+func splitWords(name string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' }) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			if !unicode.IsUpper(cur) {
+				continue
+			}
+
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
@@ -0,0 +1,107 @@
+package almiconfig
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	maxIdleConnsField   = "MaxIdleConns"
+	postgresField       = "Postgres"
+	maxIdleConnsSnake   = "POSTGRES_MAX_IDLE_CONNS"
+	maxIdleConnsKebab   = "postgres-max-idle-conns"
+	maxIdleConnsDotted  = "postgres.max_idle_conns"
+	maxIdleConnsCustom  = "Postgres/MaxIdleConns"
+	maxIdleConns        = "10"
+	maxIdleConnsV       = int(10)
+	namedHost           = "db.internal"
+	namedAPIURL         = "https://api.internal"
+	appHostKey          = "APP_HOST"
+	apiURLKey           = "HTTP_API_BASE_URL"
+	cachePrefixedTTLKey = "CACHE_TTL"
+	cacheTTL            = "30"
+	cacheTTLV           = int(30)
+)
+
+type testNamedDBConfig struct {
+	Host         string `almi:"required,env=APP_HOST"`
+	MaxIdleConns int
+}
+
+type testNamedHTTPConfig struct {
+	APIBaseURL string
+}
+
+type testNamedCacheConfig struct {
+	TTL int
+}
+
+type testNamedConfig struct {
+	Postgres testNamedDBConfig
+	HTTP     testNamedHTTPConfig
+	Cache    *testNamedCacheConfig `almi:"prefix=CACHE_"`
+}
+
+func TestSplitWords(t *testing.T) {
+	words := map[string][]string{
+		maxIdleConnsField: {"Max", "Idle", "Conns"},
+		"HTTPServerURL":   {"HTTP", "Server", "URL"},
+		"APIURL":          {"APIURL"},
+		"Port2Fallback":   {"Port2", "Fallback"},
+		"max_idle":        {"max", "idle"},
+		"ID":              {"ID"},
+	}
+
+	for name, want := range words {
+		assert.Equal(t, want, splitWords(name), name)
+	}
+}
+
+func TestNamingStrategies(t *testing.T) {
+	path := []string{postgresField, maxIdleConnsField}
+	assert.Equal(t, maxIdleConnsSnake, ScreamingSnake(path))
+	assert.Equal(t, maxIdleConnsKebab, Kebab(path))
+	assert.Equal(t, maxIdleConnsDotted, Dotted(path))
+}
+
+func TestLoader_Load_Successful_DerivedNames(t *testing.T) {
+	src := MapSource{
+		appHostKey:          namedHost,
+		maxIdleConnsSnake:   maxIdleConns,
+		apiURLKey:           namedAPIURL,
+		cachePrefixedTTLKey: cacheTTL,
+	}
+
+	cfg := testNamedConfig{}
+	report, err := NewLoader(WithSources(src), WithNaming(ScreamingSnake)).Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, namedHost, cfg.Postgres.Host)
+	assert.Equal(t, maxIdleConnsV, cfg.Postgres.MaxIdleConns)
+	assert.Equal(t, namedAPIURL, cfg.HTTP.APIBaseURL)
+	assert.Equal(t, cacheTTLV, cfg.Cache.TTL)
+
+	origin, ok := report.Origin(postgresField + "." + maxIdleConnsField)
+	assert.True(t, ok)
+	assert.Equal(t, maxIdleConnsSnake, origin.Key)
+}
+
+func TestLoader_Load_Successful_CustomNaming(t *testing.T) {
+	custom := func(path []string) string { return strings.Join(path, "/") }
+	src := MapSource{appHostKey: namedHost, maxIdleConnsCustom: maxIdleConns}
+
+	cfg := testNamedConfig{}
+	_, err := NewLoader(WithSources(src), WithNaming(custom)).Load(context.Background(), &cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, maxIdleConnsV, cfg.Postgres.MaxIdleConns)
+	assert.Nil(t, cfg.Cache)
+}
+
+func TestLoader_Load_Fail_NoNamingStillNeedsEnv(t *testing.T) {
+	cfg := testNamedConfig{}
+	_, err := NewLoader(WithSources(MapSource{appHostKey: namedHost})).Load(context.Background(), &cfg)
+	assert.ErrorIs(t, err, almierrors.EnvConstraintUndefErr)
+}
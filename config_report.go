@@ -0,0 +1,90 @@
+package almiconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+)
+
+const (
+	defaultOrigin = "default"
+	unsetOrigin   = "unset"
+	deprecatedKey = " (deprecated, use %s)"
+)
+
+// FieldOrigin describes where the final value of a config field came from.
+type FieldOrigin struct {
+	// Path is the dotted path of the field in the config struct.
+	Path string
+	// Key is the key the field was looked up with, the legacy alias that matched when the value came from one.
+	Key string
+	// Preferred is the key that should be used instead of Key when Key is a legacy alias of the field.
+	Preferred string
+	// Source is the name of the source the value was read from, it is empty when no source had the key.
+	Source string
+	// Default is set when the value came from the field's 'default=' constraint.
+	Default bool
+}
+
+func (fo FieldOrigin) String() string {
+	origin := fo.Source
+	switch {
+	case fo.Default:
+		origin = defaultOrigin
+	case fo.Source == consts.EMPTY:
+		origin = unsetOrigin
+	}
+
+	key := fo.Key
+	if fo.Preferred != consts.EMPTY {
+		key += fmt.Sprintf(deprecatedKey, fo.Preferred)
+	}
+
+	return fmt.Sprintf("%s: %s from %s", fo.Path, key, origin)
+}
+
+// Report is the provenance of every field filled by a Loader, in the order the fields were loaded.
+type Report struct {
+	Fields []FieldOrigin
+}
+
+// Origin returns the origin of the field at the dotted path.
+func (r *Report) Origin(path string) (FieldOrigin, bool) {
+	for _, fo := range r.Fields {
+		if fo.Path == path {
+			return fo, true
+		}
+	}
+
+	return FieldOrigin{}, false
+}
+
+// String formats the report with one field per line, so it can be logged at startup.
+func (r *Report) String() string {
+	lines := make([]string, 0, len(r.Fields))
+	for _, fo := range r.Fields {
+		lines = append(lines, fo.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (r *Report) record(val *configValue, cc *configConstraint) {
+	fo := FieldOrigin{
+		Path:      val.Path,
+		Key:       cc.EnvName,
+		Preferred: cc.Preferred,
+		Default:   cc.DefaultUsed,
+	}
+	if fo.Key == consts.EMPTY {
+		fo.Key = strings.Join(cc.Path, pathSep)
+	}
+
+	if m, present, _ := cc.find(); present && !fo.Default {
+		fo.Key = m.key
+		fo.Source = m.source
+	}
+
+	r.Fields = append(r.Fields, fo)
+}
@@ -0,0 +1,101 @@
+package almiconfig
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+)
+
+const (
+	envSourceName  = "env"
+	mapSourceName  = "map"
+	funcSourceName = "func"
+)
+
+// Source provides the raw string values config fields are read from.
+// Lookup reports whether key was present in the source at all, so an empty value can be told apart from a missing one.
+type Source interface {
+	Lookup(key string) (string, bool)
+}
+
+// EnvSource reads values from the process environment.
+type EnvSource struct{}
+
+func (EnvSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (EnvSource) Name() string {
+	return envSourceName
+}
+
+// MapSource reads values from an in-memory map, it is mostly useful for tests and for values built at runtime.
+type MapSource map[string]string
+
+func (ms MapSource) Lookup(key string) (string, bool) {
+	val, ok := ms[key]
+	return val, ok
+}
+
+func (MapSource) Name() string {
+	return mapSourceName
+}
+
+// FuncSource adapts an ordinary function to the Source interface.
+type FuncSource func(key string) (string, bool)
+
+func (fs FuncSource) Lookup(key string) (string, bool) {
+	return fs(key)
+}
+
+func (FuncSource) Name() string {
+	return funcSourceName
+}
+
+type namedSource struct {
+	Source
+	name string
+}
+
+// Named gives src a name, the name is what the load Report shows as the origin of the values read from src.
+func Named(name string, src Source) Source {
+	return &namedSource{Source: src, name: name}
+}
+
+func (ns *namedSource) Name() string {
+	return ns.name
+}
+
+// sourceName returns the name of src, sources without a Name method are named after their type.
+func sourceName(src Source) string {
+	if named, ok := src.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+
+	return fmt.Sprintf("%T", src)
+}
+
+// sourceChain tries its sources in order and returns the first value found.
+type sourceChain []Source
+
+func (sc sourceChain) Lookup(key string) (string, bool) {
+	for _, src := range sc {
+		if val, ok := src.Lookup(key); ok {
+			return val, true
+		}
+	}
+
+	return consts.EMPTY, false
+}
+
+// hasPathSource reports whether any of the sources looks fields up by their document path.
+func (sc sourceChain) hasPathSource() bool {
+	for _, src := range sc {
+		if _, ok := asPathSource(src); ok {
+			return true
+		}
+	}
+
+	return false
+}
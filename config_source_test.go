@@ -0,0 +1,100 @@
+package almiconfig
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	missingKey         = "MISSING"
+	mapAccessSecret    = "map_access_secret"
+	funcRefreshSecret  = "func_refresh_secret"
+	sourceKafkaBrokers = "source1,source2"
+)
+
+var sourceKafkaBrokersSlice = []string{"source1", "source2"}
+
+func TestEnvSource_Lookup(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, accessSecretEnv, accessSecret)
+
+	val, ok := EnvSource{}.Lookup(accessSecretEnv)
+	assert.True(t, ok)
+	assert.Equal(t, accessSecret, val)
+
+	_, ok = EnvSource{}.Lookup(missingKey)
+	assert.False(t, ok)
+}
+
+func TestMapSource_Lookup(t *testing.T) {
+	src := MapSource{accessSecretEnv: empty}
+
+	val, ok := src.Lookup(accessSecretEnv)
+	assert.True(t, ok)
+	assert.Equal(t, empty, val)
+
+	_, ok = src.Lookup(missingKey)
+	assert.False(t, ok)
+}
+
+func TestFuncSource_Lookup(t *testing.T) {
+	src := FuncSource(func(key string) (string, bool) {
+		return key, key == accessSecretEnv
+	})
+
+	val, ok := src.Lookup(accessSecretEnv)
+	assert.True(t, ok)
+	assert.Equal(t, accessSecretEnv, val)
+
+	_, ok = src.Lookup(missingKey)
+	assert.False(t, ok)
+}
+
+func TestLoad_Successful_SourcesTriedInOrder(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, accessSecretEnv, accessSecret)
+
+	cfg := testConfig{}
+	err := Load(context.Background(), &cfg,
+		MapSource{accessSecretEnv: mapAccessSecret, kafkaBrokersEnv: sourceKafkaBrokers},
+		FuncSource(func(key string) (string, bool) {
+			if key == refreshSecretEnv {
+				return funcRefreshSecret, true
+			}
+			return empty, false
+		}),
+		EnvSource{},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, mapAccessSecret, cfg.AccessSecret)
+	assert.Equal(t, funcRefreshSecret, cfg.RefreshSecret)
+	assert.Equal(t, sourceKafkaBrokersSlice, cfg.KafkaBrokers)
+}
+
+func TestLoad_Successful_DoesNotTouchEnvironment(t *testing.T) {
+	os.Clearenv()
+
+	cfg := testConfig{}
+	err := Load(context.Background(), &cfg, MapSource{accessSecretEnv: mapAccessSecret, kafkaBrokersEnv: sourceKafkaBrokers})
+	assert.Nil(t, err)
+	assert.Empty(t, os.Environ())
+}
+
+func TestLoad_Fail_ConfigUntouchedOnError(t *testing.T) {
+	cfg := testConfig{AccessSecret: accessSecret}
+	err := Load(context.Background(), &cfg, MapSource{kafkaBrokersEnv: sourceKafkaBrokers})
+	assert.NotNil(t, err)
+	assert.Equal(t, testConfig{AccessSecret: accessSecret}, cfg)
+}
+
+func TestLoad_Fail_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cfg := testConfig{}
+	err := Load(ctx, &cfg, MapSource{accessSecretEnv: mapAccessSecret, kafkaBrokersEnv: sourceKafkaBrokers})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,22 +1,45 @@
 package almiconfig
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
 	"github.com/stretchr/testify/assert"
 )
 
+var labelsMap = map[string]string{"team": "core", "tier": "1"}
+
 const (
 	accessSecretEnv   = "ACCESS_SECRET"
 	refreshSecretEnv  = "REFRESH_SECRET"
 	kafkaBrokersEnv   = "KAFKA_BROKERS"
 	accessLifetimeEnv = "ACCESS_LIFETIME"
+	pgHostEnv         = "PG_HOST"
+	pgPortEnv         = "PG_PORT"
+	appPgHostEnv      = "APP_PG_HOST"
+	lifetimeEnv       = "LIFETIME"
+	enabledEnv        = "ENABLED"
+	brokersEnv        = "BROKERS"
+	startedAtEnv      = "STARTED_AT"
+	dayEnv            = "DAY"
+	letterEnv         = "LETTER"
+	limitsEnv         = "LIMITS"
+	labelsEnv         = "LABELS"
+	delaysEnv         = "DELAYS"
+	labels            = "team=core;tier=1"
 
 	accessSecret               = "access_secret"
 	refreshSecret              = "refresh_secret"
 	kafkaBrokers               = "broker1,broker2,broker3"
 	accessLifetimeDefaultValue = int(10)
+	pgHost                     = "postgres"
+	pgPort                     = "5432"
+	pgPortValue                = int(5432)
+	nestedRequiredErr          = "AlmiConfigError: Field: 'Postgres.Host', is required (key: 'PG_HOST')"
+	blank                      = "   "
 )
 
 type testConfig struct {
@@ -47,6 +70,10 @@ type testConfigTypeMismatch struct {
 	AccessLifetime int `almi:"required,env=KAFKA_BROKERS,type=string"`
 }
 
+type testConfigOutOfRange struct {
+	AccessLifetime int8 `almi:"env=ACCESS_LIFETIME,type=int8"`
+}
+
 type testConfigDefaultValueWithRequiredEnv struct {
 	AccessLifetime int `almi:"required,env=ACCESS_LIFETIME,type=int,default=10"`
 }
@@ -59,6 +86,94 @@ type testConfigDefaultValueWithTypeConstraintTypeMismatch struct {
 	AccessLifetime int `almi:"required,env=ACCESS_LIFETIME,type=bool,default=true"`
 }
 
+type testDBConfig struct {
+	Host string `almi:"required,env=HOST"`
+	Port int    `almi:"env=PORT,type=int"`
+}
+
+type testConfigNested struct {
+	AccessSecret string       `almi:"required,env=ACCESS_SECRET"`
+	Postgres     testDBConfig `almi:"prefix=PG_"`
+}
+
+type testConfigNestedPrefixes struct {
+	App struct {
+		Postgres testDBConfig `almi:"prefix=PG_"`
+	} `almi:"prefix=APP_"`
+}
+
+type testConfigEmbedded struct {
+	testDBConfig `almi:"prefix=PG_"`
+}
+
+type testConfigPrefixOnNonStruct struct {
+	AccessSecret string `almi:"env=ACCESS_SECRET,prefix=PG_"`
+}
+
+type testConfigEnvOnStruct struct {
+	Postgres testDBConfig `almi:"env=POSTGRES"`
+}
+
+type testConfigRequiredTypes struct {
+	Lifetime int      `almi:"required,env=LIFETIME,type=int"`
+	Enabled  bool     `almi:"required,env=ENABLED,type=bool"`
+	Brokers  []string `almi:"required,env=BROKERS,type=[,]string"`
+}
+
+type testConfigRequiredString struct {
+	AccessSecret string `almi:"required,env=ACCESS_SECRET"`
+}
+
+type testConfigNotEmpty struct {
+	AccessSecret string `almi:"env=ACCESS_SECRET,notempty"`
+}
+
+type testConfigRequiredNested struct {
+	Postgres struct {
+		Host string `almi:"env=HOST"`
+		Port int    `almi:"env=PORT,type=int"`
+	} `almi:"required,prefix=PG_"`
+}
+
+type testConfigTimeTypes struct {
+	Timeout   time.Duration   `almi:"env=TIMEOUT,type=duration,default=30s"`
+	Backoff   []time.Duration `almi:"env=BACKOFF,type=[,]duration,default=[1s,5s,30s]"`
+	StartedAt time.Time       `almi:"required,env=STARTED_AT,type=time"`
+	Day       time.Time       `almi:"env=DAY,type=time,layout=DateOnly"`
+	Letter    rune            `almi:"env=LETTER,type=rune"`
+}
+
+type testConfigMap struct {
+	Limits map[string]int          `almi:"env=LIMITS,type={,:}map[string]int,default={a:1,b:2}"`
+	Labels map[string]string       `almi:"env=LABELS,type={;=}map[string]string"`
+	Delays map[uint8]time.Duration `almi:"env=DELAYS,type={;:}map[uint8]duration"`
+}
+
+type testConfigMapTypeMismatch struct {
+	Limits map[string]int `almi:"env=LIMITS,type={,:}map[string]uint"`
+}
+
+type testConfigPointers struct {
+	Lifetime *int           `almi:"env=LIFETIME,type=int"`
+	Timeout  *time.Duration `almi:"env=TIMEOUT,type=duration"`
+	Backoff  *time.Duration `almi:"env=BACKOFF,type=duration,default=5s"`
+	Brokers  *[]string      `almi:"env=BROKERS,type=[,]string"`
+	Postgres *testDBConfig  `almi:"prefix=PG_"`
+}
+
+type testConfigRequiredPointer struct {
+	Postgres *testDBConfig `almi:"required,prefix=PG_"`
+}
+
+type testBadTagDBConfig struct {
+	Host string `almi:"requird,env=HOST"`
+	Port string `almi:"env=PORT,type=int"`
+}
+
+type testConfigBadTagPointer struct {
+	Postgres *testBadTagDBConfig `almi:"prefix=PG_"`
+}
+
 func TestValidateConfig_Successful(t *testing.T) {
 	os.Clearenv()
 
@@ -190,3 +305,225 @@ func TestValidateConfig_Fail_DefaultValueWithTypeConstraintTypeMismatch(t *testi
 	assert.Nil(t, cfg)
 	assert.NotNil(t, err)
 }
+
+func TestValidateConfig_Successful_NestedStructWithPrefix(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, accessSecretEnv, accessSecret)
+	testSetEnv(t, pgHostEnv, pgHost)
+	testSetEnv(t, pgPortEnv, pgPort)
+
+	cfg, err := ValidateConfig(testConfigNested{})
+	assert.Nil(t, err)
+	assert.Equal(t, pgHost, cfg.Postgres.Host)
+	assert.Equal(t, pgPortValue, cfg.Postgres.Port)
+}
+
+func TestValidateConfig_Successful_NestedPrefixesAreJoined(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, appPgHostEnv, pgHost)
+
+	cfg, err := ValidateConfig(testConfigNestedPrefixes{})
+	assert.Nil(t, err)
+	assert.Equal(t, pgHost, cfg.App.Postgres.Host)
+}
+
+func TestValidateConfig_Successful_EmbeddedStruct(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, pgHostEnv, pgHost)
+
+	cfg, err := ValidateConfig(testConfigEmbedded{})
+	assert.Nil(t, err)
+	assert.Equal(t, pgHost, cfg.Host)
+}
+
+func TestValidateConfig_Fail_NestedRequiredReportsFieldPath(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, accessSecretEnv, accessSecret)
+
+	cfg, err := ValidateConfig(testConfigNested{})
+	assert.Nil(t, cfg)
+	assert.EqualError(t, err, nestedRequiredErr)
+}
+
+func TestValidateConfig_Fail_PrefixOnNonStruct(t *testing.T) {
+	os.Clearenv()
+	cfg, err := ValidateConfig(testConfigPrefixOnNonStruct{})
+	assert.Nil(t, cfg)
+	assert.NotNil(t, err)
+}
+
+func TestValidateConfig_Fail_EnvOnStruct(t *testing.T) {
+	os.Clearenv()
+	cfg, err := ValidateConfig(testConfigEnvOnStruct{})
+	assert.Nil(t, cfg)
+	assert.NotNil(t, err)
+}
+
+func TestValidateConfig_Successful_EnvOverridesDefaultValue(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, accessLifetimeEnv, oneStr)
+
+	cfg, err := ValidateConfig(testConfigDefaultValueWithRequiredEnv{})
+	assert.Nil(t, err)
+	assert.Equal(t, one, cfg.AccessLifetime)
+}
+
+func TestValidateConfig_Fail_OutOfRange(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, accessLifetimeEnv, outOfRangeVal)
+
+	cfg, err := ValidateConfig(testConfigOutOfRange{})
+	assert.Nil(t, cfg)
+
+	var rangeErr *almierrors.RangeError
+	assert.ErrorAs(t, err, &rangeErr)
+	assert.Equal(t, "AccessLifetime", rangeErr.Field)
+}
+
+func TestValidateConfig_Fail_RequiredMissingForEveryType(t *testing.T) {
+	present := map[string]string{
+		lifetimeEnv: oneStr,
+		enabledEnv:  boolVal,
+		brokersEnv:  kafkaBrokers,
+	}
+
+	for missing := range present {
+		os.Clearenv()
+		for key, val := range present {
+			if key != missing {
+				testSetEnv(t, key, val)
+			}
+		}
+
+		cfg, err := ValidateConfig(testConfigRequiredTypes{})
+		assert.Nil(t, cfg, missing)
+		assert.NotNil(t, err, missing)
+	}
+}
+
+func TestValidateConfig_Successful_RequiredPresentButEmpty(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, accessSecretEnv, empty)
+
+	cfg, err := ValidateConfig(testConfigRequiredString{})
+	assert.Nil(t, err)
+	assert.Equal(t, empty, cfg.AccessSecret)
+}
+
+func TestValidateConfig_Fail_NotEmpty(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, accessSecretEnv, blank)
+
+	cfg, err := ValidateConfig(testConfigNotEmpty{})
+	assert.Nil(t, cfg)
+	assert.NotNil(t, err)
+}
+
+func TestValidateConfig_Successful_NotEmptyUnset(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := ValidateConfig(testConfigNotEmpty{})
+	assert.Nil(t, err)
+	assert.NotNil(t, cfg)
+}
+
+func TestValidateConfig_Fail_RequiredNestedStructMissing(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := ValidateConfig(testConfigRequiredNested{})
+	assert.Nil(t, cfg)
+	assert.NotNil(t, err)
+}
+
+func TestValidateConfig_Successful_RequiredNestedStructPartiallySet(t *testing.T) {
+	os.Clearenv()
+	testSetEnv(t, pgPortEnv, pgPort)
+
+	cfg, err := ValidateConfig(testConfigRequiredNested{})
+	assert.Nil(t, err)
+	assert.Equal(t, pgPortValue, cfg.Postgres.Port)
+}
+
+func TestValidateConfig_Successful_TimeTypes(t *testing.T) {
+	cfg := testConfigTimeTypes{}
+	err := Load(context.Background(), &cfg, MapSource{startedAtEnv: timeVal, dayEnv: dateVal, letterEnv: runeVal})
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, cfg.Timeout)
+	assert.Equal(t, durationSlice, cfg.Backoff)
+	assert.Equal(t, timeV, cfg.StartedAt)
+	assert.Equal(t, dateV, cfg.Day)
+	assert.Equal(t, runeA, cfg.Letter)
+}
+
+func TestValidateConfig_Successful_Maps(t *testing.T) {
+	cfg := testConfigMap{}
+	err := Load(context.Background(), &cfg, MapSource{labelsEnv: labels, delaysEnv: durationMapVal})
+	assert.Nil(t, err)
+	assert.Equal(t, stringIntMap, cfg.Limits)
+	assert.Equal(t, labelsMap, cfg.Labels)
+	assert.Equal(t, intDurationMap, cfg.Delays)
+}
+
+func TestValidateConfig_Fail_MapTypeMismatch(t *testing.T) {
+	cfg := testConfigMapTypeMismatch{}
+	err := Load(context.Background(), &cfg, MapSource{limitsEnv: mapVal})
+	assert.ErrorIs(t, err, almierrors.ErrTypeMismatch)
+}
+
+func TestValidateConfig_Successful_PointersLeftNilWhenUnset(t *testing.T) {
+	cfg := testConfigPointers{}
+	err := Load(context.Background(), &cfg, MapSource{})
+	assert.Nil(t, err)
+	assert.Nil(t, cfg.Lifetime)
+	assert.Nil(t, cfg.Timeout)
+	assert.Nil(t, cfg.Brokers)
+	assert.Nil(t, cfg.Postgres)
+	assert.Equal(t, 5*time.Second, *cfg.Backoff)
+}
+
+func TestValidateConfig_Successful_PointersAllocatedWhenSet(t *testing.T) {
+	cfg := testConfigPointers{}
+	err := Load(context.Background(), &cfg, MapSource{lifetimeEnv: zeroStr, brokersEnv: kafkaBrokers, pgHostEnv: pgHost})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, *cfg.Lifetime)
+	assert.Equal(t, []string{"broker1", "broker2", "broker3"}, *cfg.Brokers)
+	assert.Equal(t, &testDBConfig{Host: pgHost}, cfg.Postgres)
+	assert.Nil(t, cfg.Timeout)
+}
+
+func TestValidateConfig_Successful_PointerNotWrittenThrough(t *testing.T) {
+	original := &testDBConfig{Host: accessSecret}
+	cfg := testConfigPointers{Postgres: original}
+	err := Load(context.Background(), &cfg, MapSource{pgHostEnv: pgHost})
+	assert.Nil(t, err)
+	assert.Equal(t, pgHost, cfg.Postgres.Host)
+	assert.Equal(t, accessSecret, original.Host)
+}
+
+func TestValidateConfig_Fail_RequiredPointerStructMissing(t *testing.T) {
+	cfg := testConfigRequiredPointer{}
+	err := Load(context.Background(), &cfg, MapSource{})
+	assert.ErrorIs(t, err, almierrors.ErrRequired)
+}
+
+func TestValidateConfig_Fail_PointerStructRequiredFieldsEnforcedWhenSet(t *testing.T) {
+	cfg := testConfigPointers{}
+	err := Load(context.Background(), &cfg, MapSource{pgPortEnv: pgPort})
+	assert.ErrorIs(t, err, almierrors.ErrRequired)
+	assert.Nil(t, cfg.Postgres)
+}
+
+func TestValidateConfig_Fail_PointerStructTagErrorsReportedWhenUnset(t *testing.T) {
+	cfg := testConfigBadTagPointer{}
+	err := Load(context.Background(), &cfg, MapSource{})
+
+	var errs almierrors.Errors
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[0], almierrors.ErrInvalidTag)
+	assert.ErrorIs(t, errs[1], almierrors.ErrTypeMismatch)
+	assert.Nil(t, cfg.Postgres)
+
+	_, err = NewLoader(WithSources(MapSource{}), FailFast()).Load(context.Background(), &cfg)
+	assert.ErrorIs(t, err, almierrors.ErrInvalidTag)
+}
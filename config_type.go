@@ -0,0 +1,215 @@
+package almiconfig
+
+import (
+	"reflect"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+const (
+	defaultSeparator   = ","
+	defaultKVSeparator = ":"
+)
+
+// kindTypeNames are the type names of the built-in conversions for the kinds of the field types,
+// so named types like `type Port uint16` are converted like their underlying type.
+var kindTypeNames = map[reflect.Kind]string{
+	reflect.String:  _string,
+	reflect.Bool:    _bool,
+	reflect.Int:     _int,
+	reflect.Int8:    _int8,
+	reflect.Int16:   _int16,
+	reflect.Int32:   _int32,
+	reflect.Int64:   _int64,
+	reflect.Uint:    _uint,
+	reflect.Uint8:   _uint8,
+	reflect.Uint16:  _uint16,
+	reflect.Uint32:  _uint32,
+	reflect.Uint64:  _uint64,
+	reflect.Uintptr: _uintptr,
+	reflect.Float32: _float32,
+	reflect.Float64: _float64,
+}
+
+// builtinTypeName returns the type name of the built-in conversion of t.
+func builtinTypeName(t reflect.Type) (string, bool) {
+	switch t {
+	case builtinTypes[_duration]:
+		return _duration, true
+	case builtinTypes[_time]:
+		return _time, true
+	}
+
+	name, ok := kindTypeNames[t.Kind()]
+	return name, ok
+}
+
+// resolveType works out how the field is converted from the Go type of the field.
+// Slices are split on ',' and maps on ',' and ':' unless the 'type=' constraint sets the separators.
+// An explicit 'type=' is an assertion, it must agree with the field type.
+func (cc *configConstraint) resolveType(cfg reflect.Value, val *configValue) error {
+	t := cc.FieldType
+
+	if cc.Type != consts.EMPTY {
+		tagType, ok := cc.tagType()
+		if !ok {
+			return almierrors.UnrecognizedTypeErr.Build(cc.Type)
+		}
+
+		if !compatible(tagType, t) {
+			return cc.typeMismatchErr(cfg, val)
+		}
+
+		return nil
+	}
+
+	// types decoding themselves as a whole, like time.Time or net.IP
+	if !cc.SliceType && !cc.MapType {
+		if cc.Decode = cc.decoderFor(t); cc.Decode != nil {
+			return nil
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		if cc.MapType {
+			return cc.typeMismatchErr(cfg, val)
+		}
+
+		if !cc.SliceType {
+			cc.SliceType = true
+			cc.Separator = defaultSeparator
+		}
+
+		if cc.Decode = cc.decoderFor(t.Elem()); cc.Decode != nil {
+			return nil
+		}
+
+		return cc.inferTypeName(t.Elem(), &cc.Type)
+	case reflect.Map:
+		if cc.SliceType {
+			return cc.typeMismatchErr(cfg, val)
+		}
+
+		if !cc.MapType {
+			cc.MapType = true
+			cc.Separator = defaultSeparator
+			cc.KVSeparator = defaultKVSeparator
+		}
+
+		if err := cc.inferTypeName(t.Key(), &cc.KeyType); err != nil {
+			return err
+		}
+
+		return cc.inferTypeName(t.Elem(), &cc.Type)
+	}
+
+	if cc.SliceType || cc.MapType {
+		return cc.typeMismatchErr(cfg, val)
+	}
+
+	return cc.inferTypeName(t, &cc.Type)
+}
+
+func (cc *configConstraint) inferTypeName(t reflect.Type, typeName *string) error {
+	name, ok := builtinTypeName(t)
+	if !ok {
+		return almierrors.UnrecognizedTypeErr.Build(t.String())
+	}
+
+	*typeName = name
+	return nil
+}
+
+// tagType returns the Go type the built-in conversions produce for the 'type=' constraint.
+func (cc *configConstraint) tagType() (reflect.Type, bool) {
+	elem, ok := builtinTypes[cc.Type]
+	if !ok {
+		return nil, false
+	}
+
+	switch {
+	case cc.MapType:
+		key, ok := builtinTypes[cc.KeyType]
+		if !ok {
+			return nil, false
+		}
+		return reflect.MapOf(key, elem), true
+	case cc.SliceType:
+		return reflect.SliceOf(elem), true
+	}
+
+	return elem, true
+}
+
+func (cc *configConstraint) typeMismatchErr(cfg reflect.Value, val *configValue) error {
+	return almierrors.FieldStructTagTypeMismatchErr.Build(
+		val.Path,
+		val.Field.Type.String(),
+		cfg.Type().String(),
+		cc.Type,
+		cfg.Type().String(),
+	)
+}
+
+// compatible reports whether values of tagType can be converted to fieldType,
+// which is the case when both have the same shape of kinds, like a named `type Port uint16` and uint16.
+func compatible(tagType, fieldType reflect.Type) bool {
+	if tagType.Kind() != fieldType.Kind() {
+		return false
+	}
+
+	switch tagType.Kind() {
+	case reflect.Slice:
+		return compatible(tagType.Elem(), fieldType.Elem())
+	case reflect.Map:
+		return compatible(tagType.Key(), fieldType.Key()) && compatible(tagType.Elem(), fieldType.Elem())
+	case reflect.Struct:
+		return tagType == fieldType
+	}
+
+	return true
+}
+
+// convertTo converts v to t, converting the elements of slices and maps one by one.
+func convertTo(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if !v.IsValid() || !compatible(v.Type(), t) {
+		return reflect.Value{}, false
+	}
+
+	if v.Type() == t {
+		return v, true
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(t), true
+		}
+
+		converted := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem, _ := convertTo(v.Index(i), t.Elem())
+			converted.Index(i).Set(elem)
+		}
+
+		return converted, true
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(t), true
+		}
+
+		converted := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, _ := convertTo(iter.Key(), t.Key())
+			elem, _ := convertTo(iter.Value(), t.Elem())
+			converted.SetMapIndex(key, elem)
+		}
+
+		return converted, true
+	}
+
+	return v.Convert(t), true
+}
@@ -0,0 +1,134 @@
+package almiconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	portEnv          = "PORT"
+	portsEnv         = "PORTS"
+	portMapEnv       = "PORT_MAP"
+	timeoutEnv       = "TIMEOUT"
+	ratioEnv         = "RATIO"
+	enabled          = "true"
+	ports            = "80,443"
+	portMap          = "http:80,https:443"
+	timeout          = "1m30s"
+	ratio            = "0.75"
+	portValue        = testPort(5432)
+	ratioValue       = float32(0.75)
+	tooBigPort       = "70000"
+	semicolonBrokers = "broker1;broker2"
+)
+
+var (
+	semicolonBrokersSlice = []string{"broker1", "broker2"}
+	portsValue            = []testPort{80, 443}
+	portMapValue          = map[testEnvName]testPort{"http": 80, "https": 443}
+)
+
+type testPort uint16
+
+type testEnvName string
+
+type testConfigInferredTypes struct {
+	Port    testPort                 `almi:"env=PORT"`
+	Ports   []testPort               `almi:"env=PORTS"`
+	PortMap map[testEnvName]testPort `almi:"env=PORT_MAP"`
+	Timeout time.Duration            `almi:"env=TIMEOUT"`
+	Ratio   float32                  `almi:"env=RATIO"`
+	Enabled bool                     `almi:"env=ENABLED"`
+	Brokers []string                 `almi:"env=BROKERS,type=[;]"`
+}
+
+type testConfigTypeAssertion struct {
+	Port  testPort   `almi:"env=PORT,type=uint16"`
+	Ports []testPort `almi:"env=PORTS,type=[,]uint16"`
+}
+
+type testConfigTypeConflict struct {
+	Port testPort `almi:"env=PORT,type=int"`
+}
+
+type testConfigSliceTypeOnScalar struct {
+	Port testPort `almi:"env=PORT,type=[,]"`
+}
+
+type testConfigUnsupportedType struct {
+	Events chan string `almi:"env=EVENTS"`
+}
+
+func TestResolveType_Successful_InferredFromFieldType(t *testing.T) {
+	cfg := testConfigInferredTypes{}
+	err := Load(context.Background(), &cfg, MapSource{
+		portEnv:    pgPort,
+		portsEnv:   ports,
+		portMapEnv: portMap,
+		timeoutEnv: timeout,
+		ratioEnv:   ratio,
+		enabledEnv: enabled,
+		brokersEnv: semicolonBrokers,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, portValue, cfg.Port)
+	assert.Equal(t, portsValue, cfg.Ports)
+	assert.Equal(t, portMapValue, cfg.PortMap)
+	assert.Equal(t, durationV, cfg.Timeout)
+	assert.Equal(t, ratioValue, cfg.Ratio)
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, semicolonBrokersSlice, cfg.Brokers)
+}
+
+type testConfigUnsetSlices struct {
+	Ports     []int           `almi:"env=PORTS"`
+	Brokers   []string        `almi:"env=BROKERS"`
+	Delays    []time.Duration `almi:"env=DELAYS"`
+	Enabled   []bool          `almi:"env=ENABLED"`
+	StartedAt []time.Time     `almi:"env=STARTED_AT"`
+}
+
+func TestResolveType_Successful_UnsetSliceWithoutDefault(t *testing.T) {
+	for _, src := range []MapSource{{}, {portsEnv: consts.EMPTY, brokersEnv: consts.EMPTY}} {
+		cfg := testConfigUnsetSlices{}
+		err := Load(context.Background(), &cfg, src)
+		assert.Nil(t, err)
+		assert.Equal(t, []int(nil), cfg.Ports)
+		assert.Equal(t, []string(nil), cfg.Brokers)
+		assert.Equal(t, []time.Duration(nil), cfg.Delays)
+		assert.Equal(t, []bool(nil), cfg.Enabled)
+		assert.Equal(t, []time.Time(nil), cfg.StartedAt)
+	}
+}
+
+func TestResolveType_Successful_TypeAssertionOnNamedType(t *testing.T) {
+	cfg := testConfigTypeAssertion{}
+	err := Load(context.Background(), &cfg, MapSource{portEnv: pgPort, portsEnv: ports})
+	assert.Nil(t, err)
+	assert.Equal(t, portValue, cfg.Port)
+	assert.Equal(t, portsValue, cfg.Ports)
+}
+
+func TestResolveType_Fail_OutOfRangeForUnderlyingType(t *testing.T) {
+	cfg := testConfigInferredTypes{}
+	err := Load(context.Background(), &cfg, MapSource{portEnv: tooBigPort})
+	assert.ErrorIs(t, err, almierrors.ErrOutOfRange)
+}
+
+func TestResolveType_Fail_TypeConflict(t *testing.T) {
+	for _, cfg := range []any{&testConfigTypeConflict{}, &testConfigSliceTypeOnScalar{}} {
+		_, err := NewLoader(WithSources(MapSource{portEnv: pgPort})).Load(context.Background(), cfg)
+		assert.ErrorIs(t, err, almierrors.ErrTypeMismatch)
+	}
+}
+
+func TestResolveType_Fail_UnsupportedType(t *testing.T) {
+	cfg := testConfigUnsupportedType{}
+	err := Load(context.Background(), &cfg, MapSource{})
+	assert.ErrorIs(t, err, almierrors.UnrecognizedTypeErr)
+}
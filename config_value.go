@@ -1,27 +1,74 @@
 package almiconfig
 
 import (
-	"github.com/FabianAlmos/almiconfig/lexer"
 	"reflect"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	"github.com/FabianAlmos/almiconfig/lexer"
 )
 
 type configValue struct {
 	Field       reflect.StructField
+	Path        string
 	Tag         string
 	Constraints []string
 	Value       reflect.Value
 }
 
-func getConfigValueByIndex(cfg reflect.Value, i int) *configValue {
+func getConfigValueByIndex(cfg reflect.Value, i int, parentPath string) *configValue {
 	field := cfg.Type().Field(i)
 	tag := field.Tag.Get(almi)
 
-	lxr := lexer.NewLexer(tag)
+	var constraints []string
+	if tag != consts.EMPTY {
+		lxr := lexer.NewLexer(tag)
+		constraints = lxr.Tokenize()
+	}
 
 	return &configValue{
 		Field:       field,
+		Path:        joinPath(parentPath, field.Name),
 		Tag:         tag,
-		Constraints: lxr.Tokenize(),
+		Constraints: constraints,
 		Value:       cfg.FieldByIndex([]int{i}),
 	}
 }
+
+// isStruct reports whether the field, or the value it points to, should be walked recursively
+// instead of being read as a single value. Structs with a custom decoder, like time.Time, are read as single values.
+func (val *configValue) isStruct() bool {
+	t := val.valueType()
+	return t.Kind() == reflect.Struct && decoderFor(t) == nil
+}
+
+func (val *configValue) isPointer() bool {
+	return val.Field.Type.Kind() == reflect.Pointer
+}
+
+// valueType is the type of the field, or the type it points to for pointer fields.
+func (val *configValue) valueType() reflect.Type {
+	if val.isPointer() {
+		return val.Field.Type.Elem()
+	}
+	return val.Field.Type
+}
+
+// setPointer points field to a new copy of v when ok is set, otherwise it sets field to nil.
+// A new value is allocated every time, so loading never writes through pointers of the original config.
+func setPointer(field, v reflect.Value, ok bool) {
+	if !ok {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+
+	ptr := reflect.New(field.Type().Elem())
+	ptr.Elem().Set(v)
+	field.Set(ptr)
+}
+
+func joinPath(parentPath, name string) string {
+	if parentPath == consts.EMPTY {
+		return name
+	}
+	return parentPath + "." + name
+}
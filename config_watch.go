@@ -0,0 +1,386 @@
+package almiconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+)
+
+// stamp identifies a version of a watched file or directory, modTime and size are compared on every poll
+// and the hash of the contents only when they changed, so touching a file without changing it doesn't reload.
+type stamp struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// watchedState is a version of a watched source, it is swapped as a whole when the source is read again.
+type watchedState struct {
+	src   Source
+	stamp stamp
+}
+
+// WatchedSource is a source read from a file or directory that a Watcher reads again whenever it changes.
+// Until then it serves the values of its last read.
+type WatchedSource struct {
+	path  string
+	read  func() (Source, error)
+	state atomic.Pointer[watchedState]
+}
+
+// Watch reads the file or directory at path with read, like ReadYAML or ReadDirectory, and returns a source
+// that a Watcher reads again whenever the file or directory changes.
+func Watch[S Source](path string, read func(path string) (S, error)) (*WatchedSource, error) {
+	ws := &WatchedSource{path: path, read: func() (Source, error) { return read(path) }}
+
+	st, err := ws.stat()
+	if err != nil {
+		return nil, err
+	}
+	if st.hash, err = ws.hash(); err != nil {
+		return nil, err
+	}
+
+	src, err := ws.read()
+	if err != nil {
+		return nil, err
+	}
+	ws.state.Store(&watchedState{src: src, stamp: st})
+
+	return ws, nil
+}
+
+func (ws *WatchedSource) source() Source {
+	return ws.state.Load().src
+}
+
+// Name is the name of the source read from the file or directory.
+func (ws *WatchedSource) Name() string {
+	return sourceName(ws.source())
+}
+
+func (ws *WatchedSource) Lookup(key string) (string, bool) {
+	return ws.source().Lookup(key)
+}
+
+// Path is the file or directory the source is read from.
+func (ws *WatchedSource) Path() string {
+	return ws.path
+}
+
+// changed reports whether the file or directory changed since its last read, the stamp of the current version
+// is returned to be stored once the source is read again.
+func (ws *WatchedSource) changed() (stamp, bool, error) {
+	last := ws.state.Load().stamp
+
+	st, err := ws.stat()
+	if err != nil {
+		return stamp{}, false, err
+	}
+
+	if st.modTime.Equal(last.modTime) && st.size == last.size {
+		return last, false, nil
+	}
+
+	if st.hash, err = ws.hash(); err != nil {
+		return stamp{}, false, err
+	}
+
+	return st, st.hash != last.hash, nil
+}
+
+// stat is the latest modification time and the total size of the files and directories below the path.
+func (ws *WatchedSource) stat() (stamp, error) {
+	var st stamp
+	err := walkTree(ws.path, func(_ []string, _ string, info os.FileInfo) error {
+		if info.ModTime().After(st.modTime) {
+			st.modTime = info.ModTime()
+		}
+		if !info.IsDir() {
+			st.size += info.Size()
+		}
+		return nil
+	})
+
+	return st, err
+}
+
+// hash is the hash of the names and the contents of the files below the path.
+func (ws *WatchedSource) hash() ([sha256.Size]byte, error) {
+	h := sha256.New()
+	err := walkTree(ws.path, func(keys []string, path string, info os.FileInfo) error {
+		// names and contents are terminated by a zero byte so moving bytes between them changes the hash
+		io.WriteString(h, filepath.Join(keys...)+"\x00")
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return almierrors.DirReadErr.Wrap(err, path)
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(h, f); err != nil {
+			return almierrors.DirReadErr.Wrap(err, path)
+		}
+		h.Write([]byte{0})
+
+		return nil
+	})
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, err
+}
+
+// Watcher keeps a config up to date with the files and directories of the WatchedSources of its loader.
+// It polls them for changes, loads the config again when one changes and swaps the new config in
+// only once it loaded without errors, so a broken edit keeps the last good config in place.
+type Watcher[T any] struct {
+	loader        *Loader
+	watched       []*WatchedSource
+	interval      time.Duration
+	rejectRestart bool
+	current       atomic.Pointer[T]
+
+	// reloadMu serializes the reloads, so subscribers see the configs in the order they were loaded.
+	reloadMu sync.Mutex
+
+	subsMu    sync.Mutex
+	subs      []func(old, new *T)
+	onError   []func(error)
+	onRestart []func(Changes)
+}
+
+// WatcherOption configures a Watcher.
+type WatcherOption func(*watcherOptions)
+
+type watcherOptions struct {
+	rejectRestart bool
+}
+
+// RejectRestart rejects the reloads that change fields without the 'reloadable' constraint, the config is kept
+// and the OnError handlers get an almierrors.RestartRequiredErr. By default such a reload is applied
+// and the changes that need a restart are passed to the OnRestartRequired handlers.
+func RejectRestart() WatcherOption {
+	return func(o *watcherOptions) {
+		o.rejectRestart = true
+	}
+}
+
+// NewWatcher loads the config with l and watches the WatchedSources of l every interval once Run is called.
+// It fails when the first load fails.
+func NewWatcher[T any](ctx context.Context, l *Loader, interval time.Duration, opts ...WatcherOption) (*Watcher[T], error) {
+	var o watcherOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	w := &Watcher[T]{loader: l, interval: interval, rejectRestart: o.rejectRestart}
+	for _, src := range l.sources {
+		if ns, ok := src.(*namedSource); ok {
+			src = ns.Source
+		}
+		if ws, ok := src.(*WatchedSource); ok {
+			w.watched = append(w.watched, ws)
+		}
+	}
+
+	cfg := new(T)
+	if _, err := l.Load(ctx, cfg); err != nil {
+		return nil, err
+	}
+	w.current.Store(cfg)
+
+	return w, nil
+}
+
+// Current returns the last config that loaded without errors. It is shared between the callers
+// and must not be modified, a reload swaps in a new config instead of changing it.
+func (w *Watcher[T]) Current() *T {
+	return w.current.Load()
+}
+
+// Subscribe calls fn with the old and the new config after every reload that changed the config.
+func (w *Watcher[T]) Subscribe(fn func(old, new *T)) {
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+	w.subs = append(w.subs, fn)
+}
+
+// OnError calls fn with the error of every reload that failed while the watcher runs,
+// the last good config stays current.
+func (w *Watcher[T]) OnError(fn func(error)) {
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+	w.onError = append(w.onError, fn)
+}
+
+// OnRestartRequired calls fn with the changes of the fields without the 'reloadable' constraint
+// after every reload that changed them, the program has to be restarted to apply them.
+func (w *Watcher[T]) OnRestartRequired(fn func(Changes)) {
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+	w.onRestart = append(w.onRestart, fn)
+}
+
+// Run polls the watched files and directories every interval and reloads the config when one of them changes,
+// until ctx is done. It returns the error of ctx.
+func (w *Watcher[T]) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := w.poll(ctx); err != nil {
+				w.reportError(err)
+			}
+		}
+	}
+}
+
+// Reload reads every watched source again and reloads the config, whether they changed or not.
+// The config is kept when the reload fails.
+func (w *Watcher[T]) Reload(ctx context.Context) error {
+	return w.reload(ctx, true)
+}
+
+// poll reloads the config when any of the watched sources changed.
+func (w *Watcher[T]) poll(ctx context.Context) error {
+	return w.reload(ctx, false)
+}
+
+func (w *Watcher[T]) reload(ctx context.Context, force bool) error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	prev := make([]*watchedState, len(w.watched))
+	next := make([]*watchedState, len(w.watched))
+	changed := false
+	for i, ws := range w.watched {
+		prev[i] = ws.state.Load()
+
+		st, ok, err := ws.changed()
+		if err != nil {
+			return err
+		}
+
+		next[i] = &watchedState{src: prev[i].src, stamp: st}
+		if !ok && !force {
+			continue
+		}
+
+		src, err := ws.read()
+		if err != nil {
+			// the stamp is kept, so a broken version is read again only once it changes
+			ws.state.Store(next[i])
+			return err
+		}
+		next[i].src = src
+		changed = true
+	}
+
+	// the sources switch to the new versions only once the config loaded from them is accepted,
+	// keepStamps stores just the stamps, so a rejected version is not read again until it changes
+	keepStamps := func() {
+		for i, ws := range w.watched {
+			ws.state.Store(&watchedState{src: prev[i].src, stamp: next[i].stamp})
+		}
+	}
+
+	if !changed {
+		keepStamps()
+		return nil
+	}
+
+	cfg := new(T)
+	if _, err := w.candidate(next).Load(ctx, cfg); err != nil {
+		keepStamps()
+		return err
+	}
+
+	changes, err := Diff(w.Current(), cfg)
+	if err != nil {
+		keepStamps()
+		return err
+	}
+
+	restart := changes.NotReloadable()
+	if len(restart) != 0 && w.rejectRestart {
+		keepStamps()
+		return almierrors.RestartRequiredErr.Build(strings.Join(restart.Paths(), changedPathSep))
+	}
+
+	for i, ws := range w.watched {
+		ws.state.Store(next[i])
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	old := w.current.Swap(cfg)
+
+	w.subsMu.Lock()
+	subs, onRestart := w.subs, w.onRestart
+	w.subsMu.Unlock()
+
+	for _, fn := range subs {
+		fn(old, cfg)
+	}
+
+	if len(restart) != 0 {
+		for _, fn := range onRestart {
+			fn(restart)
+		}
+	}
+
+	return nil
+}
+
+// candidate is a copy of the loader that reads the watched sources from their next versions, so the config is
+// loaded from them before the watched sources switch to them and other loads never see an unvalidated version.
+func (w *Watcher[T]) candidate(next []*watchedState) *Loader {
+	versions := make(map[*WatchedSource]Source, len(w.watched))
+	for i, ws := range w.watched {
+		versions[ws] = next[i].src
+	}
+
+	l := *w.loader
+	l.sources = make(sourceChain, 0, len(w.loader.sources))
+	for _, src := range w.loader.sources {
+		if ns, ok := src.(*namedSource); ok {
+			if ws, ok := ns.Source.(*WatchedSource); ok {
+				src = &namedSource{Source: versions[ws], name: ns.name}
+			}
+		}
+		if ws, ok := src.(*WatchedSource); ok {
+			src = versions[ws]
+		}
+		l.sources = append(l.sources, src)
+	}
+
+	return &l
+}
+
+func (w *Watcher[T]) reportError(err error) {
+	w.subsMu.Lock()
+	onError := w.onError
+	w.subsMu.Unlock()
+
+	for _, fn := range onError {
+		fn(err)
+	}
+}
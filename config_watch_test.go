@@ -0,0 +1,247 @@
+package almiconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	watchFileName     = "config.yaml"
+	watchDocument     = "postgres:\n  host: postgres\n  port: 5432\nlog_level: info\n"
+	watchNextDocument = "postgres:\n  host: postgres\n  port: 5432\nlog_level: debug\n"
+	watchBadValue     = "postgres:\n  host: postgres\n  port: port\nlog_level: warn\n"
+	watchBadDocument  = "postgres: [\n"
+	watchMovedHost    = "postgres:\n  host: replica\n  port: 5432\nlog_level: debug\n"
+	watchMovedHostV   = "replica"
+	watchLogLevel     = "info"
+	watchNextLogLevel = "debug"
+	watchLogLevelKey  = "log_level"
+	watchInterval     = 10 * time.Millisecond
+	watchTimeout      = 5 * time.Second
+)
+
+type testWatchDBConfig struct {
+	Host string `almi:"required"`
+	Port int
+}
+
+type testWatchConfig struct {
+	Postgres testWatchDBConfig
+	LogLevel string `almi:"reloadable"`
+}
+
+// writeWatched writes contents to path with a modification time after the previous write,
+// so the change is seen on file systems with a coarse timestamp resolution.
+func writeWatched(t *testing.T, path, contents string, version int) {
+	t.Helper()
+	assert.Nil(t, os.WriteFile(path, []byte(contents), dirFilePerm))
+
+	mtime := time.Now().Add(time.Duration(version) * time.Second)
+	assert.Nil(t, os.Chtimes(path, mtime, mtime))
+}
+
+func newTestWatcher(t *testing.T, opts ...WatcherOption) (*Watcher[testWatchConfig], string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), watchFileName)
+	writeWatched(t, path, watchDocument, 0)
+
+	src, err := Watch(path, ReadYAML)
+	assert.Nil(t, err)
+
+	w, err := NewWatcher[testWatchConfig](context.Background(), NewLoader(WithSources(EnvSource{}, src)), watchInterval, opts...)
+	assert.Nil(t, err)
+	assert.Equal(t, watchLogLevel, w.Current().LogLevel)
+
+	return w, path
+}
+
+func TestWatcher_Successful(t *testing.T) {
+	w, path := newTestWatcher(t)
+
+	var calls int
+	w.Subscribe(func(old, new *testWatchConfig) {
+		calls++
+		assert.Equal(t, watchLogLevel, old.LogLevel)
+		assert.Equal(t, watchNextLogLevel, new.LogLevel)
+	})
+
+	// touching the file without changing it doesn't reload
+	writeWatched(t, path, watchDocument, 1)
+	assert.Nil(t, w.poll(context.Background()))
+	assert.Equal(t, 0, calls)
+
+	writeWatched(t, path, watchNextDocument, 2)
+	assert.Nil(t, w.poll(context.Background()))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, watchNextLogLevel, w.Current().LogLevel)
+	assert.Equal(t, pgPortValue, w.Current().Postgres.Port)
+
+	assert.Nil(t, w.poll(context.Background()))
+	assert.Equal(t, 1, calls)
+}
+
+func TestWatcher_Successful_RestartRequired(t *testing.T) {
+	w, path := newTestWatcher(t)
+
+	var restart Changes
+	w.OnRestartRequired(func(changes Changes) {
+		restart = changes
+	})
+
+	writeWatched(t, path, watchNextDocument, 1)
+	assert.Nil(t, w.poll(context.Background()))
+	assert.Empty(t, restart)
+
+	writeWatched(t, path, watchMovedHost, 2)
+	assert.Nil(t, w.poll(context.Background()))
+	assert.Equal(t, []string{dirHostPath}, restart.Paths())
+	assert.Equal(t, watchMovedHostV, w.Current().Postgres.Host)
+}
+
+func TestWatcher_Successful_Run(t *testing.T) {
+	w, path := newTestWatcher(t)
+
+	reloaded := make(chan *testWatchConfig, 1)
+	w.Subscribe(func(_, new *testWatchConfig) {
+		reloaded <- new
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx)
+	}()
+
+	writeWatched(t, path, watchNextDocument, 1)
+
+	select {
+	case cfg := <-reloaded:
+		assert.Equal(t, watchNextLogLevel, cfg.LogLevel)
+	case <-time.After(watchTimeout):
+		t.Fatal("config was not reloaded")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatcher_Successful_Directory(t *testing.T) {
+	dir := t.TempDir()
+	mountVersion(t, dir, dirTimestamp, dirPassword)
+
+	src, err := Watch(dir, ReadDirectory)
+	assert.Nil(t, err)
+
+	w, err := NewWatcher[testDirConfig](context.Background(), NewLoader(WithSources(src)), watchInterval)
+	assert.Nil(t, err)
+	assert.Equal(t, dirPasswordV, w.Current().Postgres.Password)
+
+	mountVersion(t, dir, dirNextTimestamp, dirNextPasswordV)
+	assert.Nil(t, w.poll(context.Background()))
+	assert.Equal(t, dirNextPasswordV, w.Current().Postgres.Password)
+}
+
+func TestWatcher_Fail_KeepsLastGoodConfig(t *testing.T) {
+	w, path := newTestWatcher(t)
+	good := w.Current()
+
+	w.Subscribe(func(_, _ *testWatchConfig) {
+		t.Error("a failed reload notified the subscribers")
+	})
+
+	writeWatched(t, path, watchBadValue, 1)
+	err := w.poll(context.Background())
+	assert.ErrorIs(t, err, almierrors.ErrConversion)
+	assert.Same(t, good, w.Current())
+
+	writeWatched(t, path, watchBadDocument, 2)
+	var syntaxErr *almierrors.SyntaxError
+	assert.ErrorAs(t, w.poll(context.Background()), &syntaxErr)
+	assert.Same(t, good, w.Current())
+
+	// a broken version is not read again until it changes
+	assert.Nil(t, w.poll(context.Background()))
+
+	assert.Nil(t, os.Remove(path))
+	assert.ErrorIs(t, w.poll(context.Background()), almierrors.DirReadErr)
+	assert.Same(t, good, w.Current())
+}
+
+func TestWatcher_Fail_RejectRestart(t *testing.T) {
+	w, path := newTestWatcher(t, RejectRestart())
+	good := w.Current()
+
+	writeWatched(t, path, watchMovedHost, 1)
+	assert.ErrorIs(t, w.poll(context.Background()), almierrors.RestartRequiredErr)
+	assert.Same(t, good, w.Current())
+
+	writeWatched(t, path, watchNextDocument, 2)
+	assert.Nil(t, w.poll(context.Background()))
+	assert.Equal(t, watchNextLogLevel, w.Current().LogLevel)
+}
+
+func TestWatcher_Successful_SourcesSwitchAfterValidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), watchFileName)
+	writeWatched(t, path, watchDocument, 0)
+
+	src, err := Watch(path, ReadYAML)
+	assert.Nil(t, err)
+
+	// probe records the log level the watched source serves while the config is loaded
+	var seen []string
+	probe := FuncSource(func(string) (string, bool) {
+		val, _ := src.Lookup(watchLogLevelKey)
+		seen = append(seen, val)
+		return consts.EMPTY, false
+	})
+
+	type probedConfig struct {
+		Postgres testWatchDBConfig
+		LogLevel string `almi:"env=LOG_LEVEL,reloadable"`
+	}
+
+	w, err := NewWatcher[probedConfig](context.Background(), NewLoader(WithSources(probe, src)), watchInterval, RejectRestart())
+	assert.Nil(t, err)
+
+	writeWatched(t, path, watchMovedHost, 1)
+	seen = nil
+	assert.ErrorIs(t, w.poll(context.Background()), almierrors.RestartRequiredErr)
+	assert.NotEmpty(t, seen)
+	for _, val := range seen {
+		assert.Equal(t, watchLogLevel, val)
+	}
+	val, _ := src.Lookup(watchLogLevelKey)
+	assert.Equal(t, watchLogLevel, val)
+
+	writeWatched(t, path, watchNextDocument, 2)
+	seen = nil
+	assert.Nil(t, w.poll(context.Background()))
+	assert.NotEmpty(t, seen)
+	for _, val := range seen {
+		assert.Equal(t, watchLogLevel, val)
+	}
+	val, _ = src.Lookup(watchLogLevelKey)
+	assert.Equal(t, watchNextLogLevel, val)
+	assert.Equal(t, watchNextLogLevel, w.Current().LogLevel)
+}
+
+func TestNewWatcher_Fail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), watchFileName)
+	writeWatched(t, path, watchBadValue, 0)
+
+	src, err := Watch(path, ReadYAML)
+	assert.Nil(t, err)
+
+	_, err = NewWatcher[testWatchConfig](context.Background(), NewLoader(WithSources(src)), watchInterval)
+	assert.ErrorIs(t, err, almierrors.ErrConversion)
+
+	_, err = Watch(filepath.Join(t.TempDir(), watchFileName), ReadYAML)
+	assert.ErrorIs(t, err, almierrors.DirReadErr)
+}
@@ -10,9 +10,15 @@ const (
 	findVerbs  = "(%(v|(#v)|T|t|[b-d]|o|0|q|x|X|U|[e-g]|[E-G]|s|p|(([0-9]|.)*f)))"
 )
 
+// AlmiErrorMsg is the message template of an AlmiError, every template is also a sentinel error
+// that errors.Is matches against the AlmiErrors built from it.
 type AlmiErrorMsg string
 
-type almiError struct {
+// AlmiError is an error built from an AlmiErrorMsg template, optionally wrapping the error that caused it.
+type AlmiError struct {
+	Msg AlmiErrorMsg
+	Err error
+
 	msg string
 }
 
@@ -22,6 +28,9 @@ const (
 	SepStrErr             AlmiErrorMsg = "separator must be specified for AlmiStr func when 'val' is of type []T"
 	SepAtobErr            AlmiErrorMsg = "separator must be specified for AlmiAtob func when 'val' is of type []T"
 	SepAtoRBErr           AlmiErrorMsg = "separator must be specified for AlmiAtoRB func when 'val' is of type []T"
+	SepDurationErr        AlmiErrorMsg = "separator must be specified for AlmiDuration func when 'val' is of type []T"
+	SepTimeErr            AlmiErrorMsg = "separator must be specified for AlmiTime func when 'val' is of type []T"
+	SepDecoderErr         AlmiErrorMsg = "separator must be specified for custom decoders when 'val' is of type []T"
 	AtoRBConversionFailed AlmiErrorMsg = "failed to convert string to int to convert to rune/byte"
 
 	// config errors
@@ -31,27 +40,78 @@ const (
 	FieldStructTagTypeMismatchErr AlmiErrorMsg = "Field: '%s' Type: '%s' in '%s' struct does not match the constraint Type: '%s' in '%s' struct tag"
 	EnvConstraintUndefErr         AlmiErrorMsg = "'env=' constraint must be defined for all fields of the config, constraint not found for field: '%s'"
 	FieldRequiredErr              AlmiErrorMsg = "Field: '%s', is required"
+	FieldEmptyErr                 AlmiErrorMsg = "Field: '%s', must not be empty, but '%s' is set to an empty value"
+	StructRequiredErr             AlmiErrorMsg = "Field: '%s', is required, but none of its fields are set"
 	FailedToConvertTypeErr        AlmiErrorMsg = "failed to convert type of '%s' to %s from string"
 	FailedToConvertDefaultTypeErr AlmiErrorMsg = "failed to convert default value '%s' for config field '%s', expected %s type default value"
+	OutOfRangeErr                 AlmiErrorMsg = "Field: '%s': value '%s' is out of range for type '%s'"
+	MapSepUndefErr                AlmiErrorMsg = "Field: '%s': map types must specify a pair and a key-value separator character in their braces, like: {,:}map[K]V"
+	MapDefaultValueFormatErr      AlmiErrorMsg = "map default value: %s must have opening and closing braces, like: {...}"
+	MalformedMapPairErr           AlmiErrorMsg = "map pair: '%s' of Field: '%s' is malformed, expected a key and a value separated by '%s'"
+	DuplicateMapKeyErr            AlmiErrorMsg = "map key: '%s' of Field: '%s' is set more than once"
 	SliceDefaultValueFormatErr    AlmiErrorMsg = "slice default value: %s must have opening and closing brackets, like: [...]"
+	PrefixOnNonStructErr          AlmiErrorMsg = "Field: '%s': 'prefix=' constraint can only be set on struct fields"
+	InvalidConfigErr              AlmiErrorMsg = "AlmiConfig: config must be a non-nil pointer to a struct, got: '%v'"
+	StructConstraintErr           AlmiErrorMsg = "Field: '%s' is a struct, only the 'prefix=', 'key=' and 'required' constraints can be set on it"
+	EnvAliasEmptyErr              AlmiErrorMsg = "Field: '%s': 'env=' constraint has an empty name in: '%s'"
+	ExpandSyntaxErr               AlmiErrorMsg = "Field: '%s': malformed variable reference in: '%s'"
+	ExpandCycleErr                AlmiErrorMsg = "Field: '%s': variable references form a cycle: %s"
+	ExpandUnsetErr                AlmiErrorMsg = "Field: '%s': variable '%s' is not set: %s"
+	FileReadErr                   AlmiErrorMsg = "Field: '%s': failed to read the value of '%s' from file: '%s'"
+	FileTooLargeErr               AlmiErrorMsg = "Field: '%s': file: '%s' of '%s' is larger than the limit of %d bytes"
+	FlagNameInvalidErr            AlmiErrorMsg = "Field: '%s': invalid flag name: '%s'"
+	FlagRedefinedErr              AlmiErrorMsg = "Field: '%s': flag: '-%s' is already defined"
+	DirReadErr                    AlmiErrorMsg = "failed to read config directory: '%s'"
+	DirFileTooLargeErr            AlmiErrorMsg = "file: '%s' of config directory is larger than the limit of %d bytes"
+	DocumentSeparatorErr          AlmiErrorMsg = "document element: '%s' holds the separator '%s' of its field and would be split apart"
+	DocumentValueErr              AlmiErrorMsg = "document value: a value of type '%T' can't be loaded, only scalars and arrays and objects of scalars can"
+	RestartRequiredErr            AlmiErrorMsg = "reload changes fields that can't be reloaded without a restart: %s"
+	BoundInvalidErr               AlmiErrorMsg = "Field: '%s': invalid bound in constraint: '%s=%s'"
+	BoundTypeErr                  AlmiErrorMsg = "Field: '%s': constraint '%s=' can't be set on fields of type '%s'"
+	ValueBoundErr                 AlmiErrorMsg = "Field: '%s': value '%s' violates the constraint: '%s=%s'"
+	LengthBoundErr                AlmiErrorMsg = "Field: '%s': length %s violates the constraint: '%s=%s'"
+
+	// config file errors
+	SyntaxErr AlmiErrorMsg = "%s:%d:%d: %s"
 )
 
-func (aem AlmiErrorMsg) Build(args ...any) *almiError {
+func (aem AlmiErrorMsg) Build(args ...any) *AlmiError {
 	msg := string(aem)
 	matches := regexp.MustCompile(findVerbs).FindAllString(msg, -1)
 	if len(matches) != len(args) {
 		msg = invalidErr + msg
 	}
 
-	return &almiError{
+	return &AlmiError{
+		Msg: aem,
 		msg: fmt.Sprintf(msg, args...),
 	}
 }
 
-func (ae *almiError) String() string {
+// Wrap works like Build, but keeps err as the cause of the built error so it can be unwrapped.
+func (aem AlmiErrorMsg) Wrap(err error, args ...any) *AlmiError {
+	ae := aem.Build(args...)
+	ae.Err = err
+	return ae
+}
+
+func (aem AlmiErrorMsg) Error() string {
+	return string(aem)
+}
+
+func (ae *AlmiError) String() string {
 	return fmt.Sprintf("AlmiConfigError: %s", ae.msg)
 }
 
-func (ae *almiError) Error() string {
+func (ae *AlmiError) Error() string {
 	return ae.String()
 }
+
+func (ae *AlmiError) Is(target error) bool {
+	msg, ok := target.(AlmiErrorMsg)
+	return ok && msg == ae.Msg
+}
+
+func (ae *AlmiError) Unwrap() error {
+	return ae.Err
+}
@@ -1,15 +1,22 @@
 package almierrors_test
 
 import (
+	"errors"
+	"strconv"
+	"testing"
+
 	almierrors "github.com/FabianAlmos/almiconfig/errors"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 const (
 	accessSecretFieldName               = "AccessSecret"
 	fieldRequiredAccessSecretErr        = "AlmiConfigError: Field: 'AccessSecret', is required"
 	fieldRequiredAccessSecretInvalidErr = "AlmiConfigError: This is an invalid error, please report it to the developer of this module, err: Field: '%!s(MISSING)', is required"
+	accessSecretKey                     = "ACCESS_SECRET"
+	fieldRequiredAccessSecretKeyErr     = "AlmiConfigError: Field: 'AccessSecret', is required (key: 'ACCESS_SECRET')"
+	intType                             = "int"
+	badValue                            = "badval"
 )
 
 func TestAlmiErrorMsg_Build_Successful(t *testing.T) {
@@ -21,3 +28,60 @@ func TestAlmiErrorMsg_Build_Fail(t *testing.T) {
 	err := almierrors.FieldRequiredErr.Build()
 	assert.Equal(t, fieldRequiredAccessSecretInvalidErr, err.String())
 }
+
+func TestFieldError_Error(t *testing.T) {
+	fe := &almierrors.FieldError{Path: accessSecretFieldName, Err: almierrors.FieldRequiredErr.Build(accessSecretFieldName)}
+	assert.Equal(t, fieldRequiredAccessSecretErr, fe.Error())
+
+	fe.Key = accessSecretKey
+	assert.Equal(t, fieldRequiredAccessSecretKeyErr, fe.Error())
+}
+
+func TestErrors_Unwrap(t *testing.T) {
+	fe := &almierrors.FieldError{Path: accessSecretFieldName, Err: errors.ErrUnsupported}
+	errs := almierrors.Errors{errors.ErrUnsupported, fe}
+
+	var fieldErr *almierrors.FieldError
+	assert.ErrorAs(t, errs, &fieldErr)
+	assert.Equal(t, fe, fieldErr)
+	assert.ErrorIs(t, errs, errors.ErrUnsupported)
+}
+
+func TestAlmiErrorMsg_IsSentinel(t *testing.T) {
+	err := almierrors.FieldRequiredErr.Build(accessSecretFieldName)
+	assert.ErrorIs(t, err, almierrors.FieldRequiredErr)
+	assert.NotErrorIs(t, err, almierrors.FieldEmptyErr)
+}
+
+func TestAlmiErrorMsg_Wrap(t *testing.T) {
+	_, cause := strconv.Atoi(badValue)
+	err := almierrors.FailedToConvertTypeErr.Wrap(cause, accessSecretKey, intType)
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.ErrorIs(t, err, almierrors.FailedToConvertTypeErr)
+
+	var numErr *strconv.NumError
+	assert.ErrorAs(t, err, &numErr)
+}
+
+func TestNewFieldError_Kinds(t *testing.T) {
+	kinds := map[almierrors.Kind]error{
+		almierrors.ErrRequired:     almierrors.FieldRequiredErr.Build(accessSecretFieldName),
+		almierrors.ErrInvalidTag:   almierrors.EnvConstraintUndefErr.Build(accessSecretFieldName),
+		almierrors.ErrTypeMismatch: almierrors.FieldStructTagTypeMismatchErr.Build(accessSecretFieldName, intType, intType, intType, intType),
+		almierrors.ErrConversion:   errors.ErrUnsupported,
+		almierrors.ErrOutOfRange:   &almierrors.RangeError{Field: accessSecretFieldName, Value: badValue, Type: intType},
+	}
+
+	for kind, err := range kinds {
+		fe := almierrors.NewFieldError(accessSecretFieldName, accessSecretKey, badValue, err)
+		assert.Equal(t, kind, fe.Kind)
+		assert.ErrorIs(t, fe, kind)
+		assert.ErrorIs(t, almierrors.Errors{fe}, kind)
+	}
+}
+
+func TestRangeError_Is(t *testing.T) {
+	err := &almierrors.RangeError{Field: accessSecretFieldName, Value: badValue, Type: intType}
+	assert.ErrorIs(t, err, almierrors.ErrOutOfRange)
+	assert.ErrorIs(t, err, strconv.ErrRange)
+}
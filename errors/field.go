@@ -0,0 +1,229 @@
+package almierrors
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const keySuffix = " (key: '%s')"
+
+// Kind is the class of a FieldError, the kinds are sentinel errors to be used with errors.Is.
+type Kind string
+
+const (
+	// ErrInvalidTag is a mistake in the almi struct tag of a field, it is a bug in the config struct itself.
+	ErrInvalidTag Kind = "invalid almi struct tag"
+	// ErrTypeMismatch is a field whose Go type doesn't match its 'type=' constraint.
+	ErrTypeMismatch Kind = "field type mismatch"
+	// ErrRequired is a required field that is not set.
+	ErrRequired Kind = "required field not set"
+	// ErrEmpty is a 'notempty' field that is set to an empty value.
+	ErrEmpty Kind = "field set to an empty value"
+	// ErrConversion is a value that can't be converted to the type of its field.
+	ErrConversion Kind = "failed to convert value"
+	// ErrOutOfRange is a value that doesn't fit into the type of its field.
+	ErrOutOfRange Kind = "value out of range"
+	// ErrFile is a value that should be read from a file which can't be read.
+	ErrFile Kind = "failed to read file"
+	// ErrBound is a value outside the bounds set by the 'min=', 'max=', 'len=', 'minlen=' or 'maxlen=' constraints.
+	ErrBound Kind = "value out of bounds"
+)
+
+var msgKinds = map[AlmiErrorMsg]Kind{
+	SepAtoiErr:                    ErrInvalidTag,
+	SepStrErr:                     ErrInvalidTag,
+	SepAtobErr:                    ErrInvalidTag,
+	SepAtoRBErr:                   ErrInvalidTag,
+	SepDurationErr:                ErrInvalidTag,
+	SepTimeErr:                    ErrInvalidTag,
+	SepDecoderErr:                 ErrInvalidTag,
+	SepUndefErr:                   ErrInvalidTag,
+	ConstraintUnknownErr:          ErrInvalidTag,
+	UnrecognizedTypeErr:           ErrInvalidTag,
+	EnvConstraintUndefErr:         ErrInvalidTag,
+	EnvAliasEmptyErr:              ErrInvalidTag,
+	ExpandSyntaxErr:               ErrConversion,
+	ExpandCycleErr:                ErrConversion,
+	ExpandUnsetErr:                ErrRequired,
+	FileReadErr:                   ErrFile,
+	FileTooLargeErr:               ErrFile,
+	FlagNameInvalidErr:            ErrInvalidTag,
+	FlagRedefinedErr:              ErrInvalidTag,
+	DirReadErr:                    ErrFile,
+	DocumentSeparatorErr:          ErrConversion,
+	DocumentValueErr:              ErrConversion,
+	DirFileTooLargeErr:            ErrFile,
+	BoundInvalidErr:               ErrInvalidTag,
+	BoundTypeErr:                  ErrInvalidTag,
+	SliceDefaultValueFormatErr:    ErrInvalidTag,
+	MapSepUndefErr:                ErrInvalidTag,
+	MapDefaultValueFormatErr:      ErrInvalidTag,
+	MalformedMapPairErr:           ErrConversion,
+	DuplicateMapKeyErr:            ErrConversion,
+	PrefixOnNonStructErr:          ErrInvalidTag,
+	StructConstraintErr:           ErrInvalidTag,
+	FieldStructTagTypeMismatchErr: ErrTypeMismatch,
+	FieldRequiredErr:              ErrRequired,
+	StructRequiredErr:             ErrRequired,
+	FieldEmptyErr:                 ErrEmpty,
+	AtoRBConversionFailed:         ErrConversion,
+	FailedToConvertTypeErr:        ErrConversion,
+	FailedToConvertDefaultTypeErr: ErrConversion,
+	OutOfRangeErr:                 ErrOutOfRange,
+}
+
+func (k Kind) Error() string {
+	return string(k)
+}
+
+// kindOf classifies err, errors that aren't built by this package are treated as conversion errors.
+func kindOf(err error) Kind {
+	var rangeErr *RangeError
+	if errors.As(err, &rangeErr) {
+		return ErrOutOfRange
+	}
+
+	var boundErr *BoundError
+	if errors.As(err, &boundErr) {
+		return ErrBound
+	}
+
+	var ae *AlmiError
+	if errors.As(err, &ae) {
+		if kind, ok := msgKinds[ae.Msg]; ok {
+			return kind
+		}
+	}
+
+	return ErrConversion
+}
+
+// RangeError is returned when a value is syntactically valid, but doesn't fit into the type of its field.
+type RangeError struct {
+	Field string
+	Value string
+	Type  string
+}
+
+func (re *RangeError) Error() string {
+	return OutOfRangeErr.Build(re.Field, re.Value, re.Type).Error()
+}
+
+func (re *RangeError) Is(target error) bool {
+	return target == ErrOutOfRange
+}
+
+func (re *RangeError) Unwrap() error {
+	return strconv.ErrRange
+}
+
+// BoundError is returned when a value is outside the bounds set by the 'min=', 'max=', 'len=', 'minlen='
+// or 'maxlen=' constraints of its field.
+type BoundError struct {
+	Field string
+	// Constraint is the name of the violated constraint, like min or maxlen.
+	Constraint string
+	// Bound is the bound set by the constraint.
+	Bound string
+	// Actual is the value of the field, or its length when Length is set.
+	Actual string
+	Length bool
+}
+
+func (be *BoundError) msg() AlmiErrorMsg {
+	if be.Length {
+		return LengthBoundErr
+	}
+	return ValueBoundErr
+}
+
+func (be *BoundError) Error() string {
+	return be.msg().Build(be.Field, be.Actual, be.Constraint, be.Bound).Error()
+}
+
+func (be *BoundError) Is(target error) bool {
+	return target == ErrBound || target == be.msg()
+}
+
+// FieldError is a problem with a single config field.
+type FieldError struct {
+	// Path is the dotted path of the field in the config struct.
+	Path string
+	// Key is the key the field is looked up with, it is empty when the field failed before the key was known.
+	Key string
+	// Value is the raw value read for the field, or its default value when that was used.
+	Value string
+	// Kind classifies the problem, errors.Is(err, Kind) matches FieldErrors of that kind.
+	Kind Kind
+	// Err is the reason the field is invalid.
+	Err error
+}
+
+// NewFieldError creates a FieldError, its Kind is derived from err.
+func NewFieldError(path, key, value string, err error) *FieldError {
+	return &FieldError{
+		Path:  path,
+		Key:   key,
+		Value: value,
+		Kind:  kindOf(err),
+		Err:   err,
+	}
+}
+
+func (fe *FieldError) Error() string {
+	if fe.Key == "" {
+		return fe.Err.Error()
+	}
+
+	return fe.Err.Error() + fmt.Sprintf(keySuffix, fe.Key)
+}
+
+func (fe *FieldError) Is(target error) bool {
+	kind, ok := target.(Kind)
+	return ok && kind == fe.Kind
+}
+
+func (fe *FieldError) Unwrap() error {
+	return fe.Err
+}
+
+// Errors holds every problem found while loading a config, one line each.
+// It supports errors.Is and errors.As, which look through all of its errors.
+type Errors []error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
+// SyntaxError is a malformed config file, like a .env file, Line and Column are 1-based.
+type SyntaxError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+	// Err is the cause of the error when it has one, like almierrors.ExpandUnsetErr for a ${VAR:?error} reference.
+	Err error
+}
+
+func (se *SyntaxError) Error() string {
+	return SyntaxErr.Build(se.File, se.Line, se.Column, se.Msg).Error()
+}
+
+func (se *SyntaxError) Is(target error) bool {
+	return target == SyntaxErr
+}
+
+func (se *SyntaxError) Unwrap() error {
+	return se.Err
+}
@@ -1,16 +1,38 @@
 package lexer
 
-import "github.com/FabianAlmos/almiconfig/consts"
+import (
+	"regexp"
+
+	"github.com/FabianAlmos/almiconfig/consts"
+)
 
 const (
 	_COMMA      = 44
 	_EQUALS     = 61
 	_LSQBRACKET = 91
 	_RSQBRACKET = 93
+	_LCBRACKET  = 123
+	_RCBRACKET  = 125
 
 	_DEFAULT_TOKEN = "default"
+	_DESC_TOKEN    = "desc"
 )
 
+// constraintStart matches the start of the constraint following a comma: a name that is followed by '=',
+// another constraint or the end of the tag. Free text after a comma starts with a space, or isn't a bare name.
+var constraintStart = regexp.MustCompile(`^[a-z_]+(=|,|$)`)
+
+// closingBrackets maps the brackets that can enclose separators and default values to their closing pair.
+var closingBrackets = map[rune]rune{
+	_LSQBRACKET: _RSQBRACKET,
+	_LCBRACKET:  _RCBRACKET,
+}
+
+func isOpeningBracket(char byte) bool {
+	_, ok := closingBrackets[rune(char)]
+	return ok
+}
+
 type Lexer struct {
 	Line  string
 	Char  rune
@@ -49,17 +71,24 @@ func (l *Lexer) Next() rune {
 func (l *Lexer) Tokenize() []string {
 	for l.HasNext() {
 		l.Next()
+		// descriptions are free text which can hold commas, they run to the next constraint or the end of the line
+		if l.Char == _EQUALS && l.Token == _DESC_TOKEN {
+			end := descEnd(l.Line[l.Index:])
+			l.Token += l.Line[l.Index-1 : l.Index+end]
+			l.Index += end
+			continue
+		}
 		if l.Char == _EQUALS && l.Token == _DEFAULT_TOKEN {
 			l.Token += string(l.Char)
 			l.Next()
-			if l.Char == _LSQBRACKET {
-				for l.HasNext() && l.Char != _RSQBRACKET {
+			if closing, ok := closingBrackets[l.Char]; ok {
+				for l.HasNext() && l.Char != closing {
 					l.Token += string(l.Char)
 					l.Next()
 				}
 			}
 		}
-		if l.Char == _COMMA && l.Token[len(l.Token)-1] != _LSQBRACKET {
+		if l.Char == _COMMA && !isOpeningBracket(l.Token[len(l.Token)-1]) {
 			l.Next()
 			l.Tokens = append(l.Tokens, l.Token)
 			l.Token = consts.EMPTY
@@ -72,3 +101,14 @@ func (l *Lexer) Tokenize() []string {
 
 	return l.Tokens
 }
+
+// descEnd returns the end of the description at the start of s, the comma before the next constraint.
+func descEnd(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == _COMMA && constraintStart.MatchString(s[i+1:]) {
+			return i
+		}
+	}
+
+	return len(s)
+}
@@ -16,6 +16,9 @@ const (
 	reqEnvAccLine                              = "required,env=ACCESS_SECRET"
 	reqEnvAccSliceTypeLine                     = "required,env=ACCESS_SECRET,type=[,]string"
 	reqEnvBrokersSliceTypeWithDefaultValueLine = "required,env=BROKERS,type=[,]string,default=[broker1,broker2,broker3]"
+	envLimitsMapTypeWithDefaultValueLine       = "env=LIMITS,type={,:}map[string]int,default={a:1,b:2}"
+	envPortWithDescLine                        = "env=PORT,default=5432,desc=port of the database, like 5432"
+	envPortWithDescBeforeRequiredLine          = "env=PORT,desc=port of the database, like 5432,required,default=5432"
 )
 
 var (
@@ -23,6 +26,9 @@ var (
 	reqEnvAcc                              = []string{"required", "env=ACCESS_SECRET"}
 	reqEnvAccSliceType                     = []string{"required", "env=ACCESS_SECRET", "type=[,]string"}
 	reqEnvBrokersSliceTypeWithDefaultValue = []string{"required", "env=BROKERS", "type=[,]string", "default=[broker1,broker2,broker3]"}
+	envLimitsMapTypeWithDefaultValue       = []string{"env=LIMITS", "type={,:}map[string]int", "default={a:1,b:2}"}
+	envPortWithDesc                        = []string{"env=PORT", "default=5432", "desc=port of the database, like 5432"}
+	envPortWithDescBeforeRequired          = []string{"env=PORT", "desc=port of the database, like 5432", "required", "default=5432"}
 )
 
 func TestNewLexer(t *testing.T) {
@@ -64,3 +70,18 @@ func TestLexer_Tokenize_SuccessfulLexDefaultWithSliceType(t *testing.T) {
 	l := lexer.NewLexer(reqEnvBrokersSliceTypeWithDefaultValueLine)
 	assert.Equal(t, reqEnvBrokersSliceTypeWithDefaultValue, l.Tokenize())
 }
+
+func TestLexer_Tokenize_SuccessfulLexDefaultWithMapType(t *testing.T) {
+	l := lexer.NewLexer(envLimitsMapTypeWithDefaultValueLine)
+	assert.Equal(t, envLimitsMapTypeWithDefaultValue, l.Tokenize())
+}
+
+func TestLexer_Tokenize_SuccessfulLexDescToEndOfLine(t *testing.T) {
+	l := lexer.NewLexer(envPortWithDescLine)
+	assert.Equal(t, envPortWithDesc, l.Tokenize())
+}
+
+func TestLexer_Tokenize_SuccessfulLexDescToNextConstraint(t *testing.T) {
+	l := lexer.NewLexer(envPortWithDescBeforeRequiredLine)
+	assert.Equal(t, envPortWithDescBeforeRequired, l.Tokenize())
+}
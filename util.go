@@ -1,19 +1,30 @@
 package almiconfig
 
 import (
-	"os"
+	"errors"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/FabianAlmos/almiconfig/consts"
 	almierrors "github.com/FabianAlmos/almiconfig/errors"
 	"golang.org/x/exp/constraints"
 )
 
-const sliceBracketsPattern = `\[.*\]`
+const (
+	sliceBracketsPattern = `\[.*\]`
+	mapBracketsPattern   = `^\{.*\}$`
+	// leadingZeroPattern matches integers like 0755, which strconv would read as octal with base 0
+	leadingZeroPattern = `^[+-]?0[0-9]`
+)
 
-var sliceBracketsRegexp = regexp.MustCompile(sliceBracketsPattern)
+var (
+	sliceBracketsRegexp = regexp.MustCompile(sliceBracketsPattern)
+	mapBracketsRegexp   = regexp.MustCompile(mapBracketsPattern)
+	leadingZeroRegexp   = regexp.MustCompile(leadingZeroPattern)
+)
 
 type intConstraint interface {
 	constraints.Signed | constraints.Unsigned
@@ -23,22 +34,99 @@ type number interface {
 	intConstraint | constraints.Float
 }
 
+// usesDefault reports whether the field's value comes from its 'default=' constraint instead of its source.
+// The default only applies when the key is not present, or when it is empty and 'default_on_empty' is set.
+func (cc *configConstraint) usesDefault(envVal string, present bool) bool {
+	if !cc.HasDefault || cc.Default == consts.EMPTY {
+		return false
+	}
+
+	return !present || (cc.DefaultOnEmpty && envVal == consts.EMPTY)
+}
+
 func getEnvVal(cc configConstraint) (string, error) {
-	envVal := os.Getenv(cc.EnvName)
-	if cc.HasDefault && cc.Default != consts.EMPTY {
-		if cc.SliceType && !sliceBracketsRegexp.MatchString(cc.Default) {
-			return "", almierrors.SliceDefaultValueFormatErr.Build(cc.Default)
-		}
+	if cc.SliceType && cc.HasDefault && !sliceBracketsRegexp.MatchString(cc.Default) {
+		return "", almierrors.SliceDefaultValueFormatErr.Build(cc.Default)
+	}
 
-		if cc.SliceType && sliceBracketsRegexp.MatchString(cc.Default) {
-			envVal = cc.Default[1 : len(cc.Default)-1]
-		} else {
+	if cc.MapType && cc.HasDefault && !mapBracketsRegexp.MatchString(cc.Default) {
+		return "", almierrors.MapDefaultValueFormatErr.Build(cc.Default)
+	}
+
+	var (
+		envVal      string
+		defaultUsed bool
+	)
+	if cc.Resolved {
+		// checkConstraints has already looked the value up, read its file, expanded it or fallen back to the default
+		envVal, defaultUsed = cc.Value, cc.DefaultUsed
+	} else {
+		var (
+			present bool
+			err     error
+		)
+		envVal, present, err = cc.lookup()
+		if err != nil {
+			return consts.EMPTY, err
+		}
+		defaultUsed = cc.usesDefault(envVal, present)
+		if defaultUsed {
 			envVal = cc.Default
 		}
 	}
+
+	if defaultUsed && (cc.SliceType || cc.MapType) {
+		envVal = envVal[1 : len(envVal)-1]
+	}
 	return envVal, nil
 }
 
+// parseNumber parses s with the strconv function matching the kind of T at T's bit size, so values that
+// don't fit are reported with a RangeError instead of silently wrapping around.
+// Integers accept the 0x, 0o and 0b prefixes and '_' digit separators, a leading 0 is still read as decimal.
+func parseNumber[T number](cc configConstraint, s string) (T, error) {
+	var (
+		n   T
+		err error
+	)
+
+	t := reflect.TypeOf(n)
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, t.Bits())
+		n = T(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(s, intBase(s), t.Bits())
+		n = T(i)
+	default:
+		var u uint64
+		u, err = strconv.ParseUint(s, intBase(s), t.Bits())
+		n = T(u)
+	}
+
+	if errors.Is(err, strconv.ErrRange) {
+		typeName := cc.Type
+		if typeName == consts.EMPTY {
+			typeName = t.String()
+		}
+		return T(0), &almierrors.RangeError{Field: cc.FieldName, Value: s, Type: typeName}
+	}
+	if err != nil {
+		return T(0), err
+	}
+
+	return n, nil
+}
+
+func intBase(s string) int {
+	if leadingZeroRegexp.MatchString(s) {
+		return 10
+	}
+	return 0
+}
+
 func aton[T number](cc configConstraint) (any, error) {
 	envVal, err := getEnvVal(cc)
 	if err != nil {
@@ -46,6 +134,9 @@ func aton[T number](cc configConstraint) (any, error) {
 	}
 
 	if !cc.Required && envVal == consts.EMPTY {
+		if cc.SliceType {
+			return []T(nil), nil
+		}
 		return T(0), nil
 	}
 
@@ -53,12 +144,12 @@ func aton[T number](cc configConstraint) (any, error) {
 		var ns []T
 		vals := strings.Split(envVal, cc.Separator)
 		for _, val := range vals {
-			n, err := strconv.Atoi(val)
+			n, err := parseNumber[T](cc, val)
 			if err != nil {
 				return T(0), err
 			}
 
-			ns = append(ns, T(n))
+			ns = append(ns, n)
 		}
 
 		return ns, nil
@@ -66,12 +157,12 @@ func aton[T number](cc configConstraint) (any, error) {
 		return T(0), almierrors.SepAtoiErr.Build()
 	}
 
-	n, err := strconv.Atoi(envVal)
+	n, err := parseNumber[T](cc, envVal)
 	if err != nil {
 		return T(0), err
 	}
 
-	return T(n), nil
+	return n, nil
 }
 
 // Generic for readability and proper zero value return
@@ -82,6 +173,9 @@ func str[T ~string](cc configConstraint) (val any, err error) {
 	}
 
 	if !cc.Required && envVal == consts.EMPTY {
+		if cc.SliceType {
+			return []T(nil), nil
+		}
 		return T(consts.EMPTY), nil
 	}
 
@@ -108,6 +202,9 @@ func atob[T ~bool](cc configConstraint) (val any, err error) {
 	}
 
 	if !cc.Required && envVal == consts.EMPTY {
+		if cc.SliceType {
+			return []T(nil), nil
+		}
 		return T(false), nil
 	}
 
@@ -143,6 +240,9 @@ func atoRB[T ~rune | ~byte](cc configConstraint) (val any, err error) {
 	}
 
 	if !cc.Required && envVal == consts.EMPTY {
+		if cc.SliceType {
+			return []T(nil), nil
+		}
 		return T(0), nil
 	}
 
@@ -150,12 +250,12 @@ func atoRB[T ~rune | ~byte](cc configConstraint) (val any, err error) {
 		var rbs []T
 		vals := strings.Split(envVal, cc.Separator)
 		for _, val := range vals {
-			n, err := strconv.Atoi(val)
+			n, err := parseRB[T](cc, val)
 			if err != nil {
-				return T(0), almierrors.AtoRBConversionFailed.Build()
+				return T(0), err
 			}
 
-			rbs = append(rbs, T(n))
+			rbs = append(rbs, n)
 		}
 
 		return rbs, nil
@@ -163,10 +263,193 @@ func atoRB[T ~rune | ~byte](cc configConstraint) (val any, err error) {
 		return T(0), almierrors.SepAtoRBErr.Build()
 	}
 
-	n, err := strconv.Atoi(envVal)
+	n, err := parseRB[T](cc, envVal)
+	if err != nil {
+		return T(0), err
+	}
+
+	return n, nil
+}
+
+func parseRB[T ~rune | ~byte](cc configConstraint, s string) (T, error) {
+	n, err := parseNumber[T](cc, s)
+	if err != nil {
+		var rangeErr *almierrors.RangeError
+		if errors.As(err, &rangeErr) {
+			return T(0), err
+		}
+		return T(0), almierrors.AtoRBConversionFailed.Wrap(err)
+	}
+
+	return n, nil
+}
+
+func atoDuration(cc configConstraint) (val any, err error) {
+	envVal, err := getEnvVal(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cc.Required && envVal == consts.EMPTY {
+		if cc.SliceType {
+			return []time.Duration(nil), nil
+		}
+		return time.Duration(0), nil
+	}
+
+	if cc.SliceType && cc.Separator != consts.EMPTY {
+		var ds []time.Duration
+		vals := strings.Split(envVal, cc.Separator)
+		for _, val := range vals {
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return time.Duration(0), err
+			}
+
+			ds = append(ds, d)
+		}
+
+		return ds, nil
+	} else if cc.SliceType && cc.Separator == consts.EMPTY {
+		return time.Duration(0), almierrors.SepDurationErr.Build()
+	}
+
+	d, err := time.ParseDuration(envVal)
+	if err != nil {
+		return time.Duration(0), err
+	}
+
+	return d, nil
+}
+
+// timeLayouts are the layouts of the time package that 'layout=' accepts by name,
+// layouts containing commas like RFC1123 can only be set by name.
+var timeLayouts = map[string]string{
+	"Layout":      time.Layout,
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// timeLayout returns the layout times are parsed with, RFC3339 is used when 'layout=' is not set.
+func (cc *configConstraint) timeLayout() string {
+	if cc.Layout == consts.EMPTY {
+		return time.RFC3339
+	}
+
+	if named, ok := timeLayouts[cc.Layout]; ok {
+		return named
+	}
+
+	return cc.Layout
+}
+
+func atoTime(cc configConstraint) (val any, err error) {
+	envVal, err := getEnvVal(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cc.Required && envVal == consts.EMPTY {
+		if cc.SliceType {
+			return []time.Time(nil), nil
+		}
+		return time.Time{}, nil
+	}
+
+	if cc.SliceType && cc.Separator != consts.EMPTY {
+		var ts []time.Time
+		vals := strings.Split(envVal, cc.Separator)
+		for _, val := range vals {
+			t, err := time.Parse(cc.timeLayout(), val)
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			ts = append(ts, t)
+		}
+
+		return ts, nil
+	} else if cc.SliceType && cc.Separator == consts.EMPTY {
+		return time.Time{}, almierrors.SepTimeErr.Build()
+	}
+
+	t, err := time.Parse(cc.timeLayout(), envVal)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return t, nil
+}
+
+// convertValue converts a single raw value to typeName through the same conversions fields use,
+// it is used for the keys and values of maps.
+func (cc *configConstraint) convertValue(typeName, raw string) (any, error) {
+	elem := configConstraint{
+		FieldName: cc.FieldName,
+		EnvName:   cc.EnvName,
+		Type:      typeName,
+		Layout:    cc.Layout,
+		Value:     raw,
+		Present:   true,
+		Resolved:  true,
+	}
+	return elem.findType()
+}
+
+func atoMap(cc configConstraint) (val any, err error) {
+	mapType, ok := cc.tagType()
+	if !ok {
+		return nil, almierrors.UnrecognizedTypeErr.Build("map[" + cc.KeyType + "]" + cc.Type)
+	}
+
+	envVal, err := getEnvVal(cc)
 	if err != nil {
-		return T(0), almierrors.AtoRBConversionFailed.Build()
+		return nil, err
+	}
+
+	if envVal == consts.EMPTY {
+		return reflect.Zero(mapType).Interface(), nil
+	}
+
+	m := reflect.MakeMap(mapType)
+	for _, pair := range strings.Split(envVal, cc.Separator) {
+		kv := strings.SplitN(pair, cc.KVSeparator, 2)
+		if len(kv) != 2 {
+			return nil, almierrors.MalformedMapPairErr.Build(pair, cc.FieldName, cc.KVSeparator)
+		}
+
+		k, err := cc.convertValue(cc.KeyType, kv[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if m.MapIndex(reflect.ValueOf(k)).IsValid() {
+			return nil, almierrors.DuplicateMapKeyErr.Build(kv[0], cc.FieldName)
+		}
+
+		v, err := cc.convertValue(cc.Type, kv[1])
+		if err != nil {
+			return nil, err
+		}
+
+		m.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v))
 	}
 
-	return T(n), nil
+	return m.Interface(), nil
 }
@@ -1,9 +1,13 @@
 package almiconfig
 
 import (
+	"context"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
+	almierrors "github.com/FabianAlmos/almiconfig/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -34,6 +38,7 @@ const (
 	ten   = uint64(10)
 
 	comma         = ","
+	colon         = ":"
 	dot           = "."
 	badStrSlice   = "0,x"
 	zeroStrSlice  = "0,0"
@@ -68,8 +73,42 @@ const (
 	badRuneVal      = "notRune"
 	runeSliceVal    = "65,65"
 	badRuneSliceVal = "65,notRune"
+	runeSliceDefVal = "[1,1]"
 	runeA           = rune(65)
 	runeFail        = rune(0)
+
+	floatKey      = "float"
+	floatVal      = "0.75"
+	floatSliceVal = "0.75,1_000.5"
+	floatV64      = float64(0.75)
+	floatV32      = float32(0.75)
+
+	durationKey         = "duration"
+	durationVal         = "1m30s"
+	badDurationVal      = "90"
+	durationSliceDefVal = "[1s,5s,30s]"
+	durationV           = 90 * time.Second
+
+	timeKey      = "time"
+	timeVal      = "2024-05-06T18:54:15Z"
+	dateVal      = "2024-05-06"
+	dateOnly     = "DateOnly"
+	dateLayout   = "2006-01-02"
+	timeSliceVal = "2024-05-06T18:54:15Z;2024-05-06T00:00:00Z"
+	semicolon    = ";"
+
+	mapKey           = "map"
+	mapVal           = "a:1,b:2"
+	mapDefaultVal    = "{a:1,b:2}"
+	badMapDefaultVal = "a:1,b:2"
+	malformedMapVal  = "a:1,b"
+	duplicateMapVal  = "a:1,a:2"
+	badMapElemVal    = "a:1,b:x"
+	durationMapVal   = "1:1s;2:1m"
+
+	intKey        = "int"
+	outOfRangeVal = "300"
+	negativeVal   = "-1"
 )
 
 var (
@@ -91,6 +130,26 @@ var (
 
 	runeSlice = []rune{65, 65}
 
+	floatSlice = []float64{0.75, 1000.5}
+
+	stringIntMap   = map[string]int{"a": 1, "b": 2}
+	intDurationMap = map[uint8]time.Duration{1: time.Second, 2: time.Minute}
+
+	durationSlice = []time.Duration{time.Second, 5 * time.Second, 30 * time.Second}
+
+	timeV     = time.Date(2024, 5, 6, 18, 54, 15, 0, time.UTC)
+	dateV     = time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	timeSlice = []time.Time{timeV, dateV}
+
+	intSyntaxVals = map[string]int{
+		"0x1F":      31,
+		"0o17":      15,
+		"0b101":     5,
+		"1_000_000": 1000000,
+		"-0x10":     -16,
+		"0755":      755,
+	}
+
 	intTypes     = []string{_uintptr, _int, _int8, _int16, _int32, _int64, _uint, _uint8, _uint16, _uint32, _uint64}
 	strVals      = []string{zeroStr, oneStr, twoStr, threeStr, fourStr, fiveStr, sixStr, sevenStr, eightStr, nineStr, tenStr}
 	badStrVals   = []string{badVal, badVal, badVal, badVal, badVal, badVal, badVal, badVal, badVal, badVal, badVal}
@@ -366,3 +425,219 @@ func TestAlmiStr_FailGetSliceDefaultValue_SeparatorMismatch(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotEqual(t, strSlice, envVar)
 }
+
+func TestGetEnvVal_SourceValueOverridesDefault(t *testing.T) {
+	cc := configConstraint{EnvName: strKey, HasDefault: true, Default: strVal, Source: MapSource{strKey: runeVal}}
+	envVar, err := str[string](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, runeVal, envVar)
+}
+
+func TestGetEnvVal_DefaultUsedWhenUnset(t *testing.T) {
+	cc := configConstraint{EnvName: strKey, HasDefault: true, Default: strVal, Source: MapSource{}}
+	envVar, err := str[string](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, strVal, envVar)
+}
+
+func TestGetEnvVal_EmptyValueKeptWithoutDefaultOnEmpty(t *testing.T) {
+	cc := configConstraint{EnvName: strKey, HasDefault: true, Default: strVal, Source: MapSource{strKey: empty}}
+	envVar, err := str[string](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, empty, envVar)
+}
+
+func TestGetEnvVal_DefaultUsedWhenEmptyWithDefaultOnEmpty(t *testing.T) {
+	cc := configConstraint{EnvName: strKey, HasDefault: true, Default: strVal, DefaultOnEmpty: true, Source: MapSource{strKey: empty}}
+	envVar, err := str[string](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, strVal, envVar)
+}
+
+func TestGetEnvVal_SourceSliceOverridesDefault(t *testing.T) {
+	cc := configConstraint{EnvName: runeKey, SliceType: true, Separator: comma, HasDefault: true, Default: runeSliceDefVal, Source: MapSource{runeKey: runeSliceVal}}
+	envVar, err := atoRB[rune](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, runeSlice, envVar)
+}
+
+func TestGetEnvVal_SliceDefaultUsedWhenUnset(t *testing.T) {
+	cc := configConstraint{EnvName: strKey, SliceType: true, Separator: comma, HasDefault: true, Default: strSliceDefaultVal, Source: MapSource{}}
+	envVar, err := str[string](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, strSlice, envVar)
+}
+
+func TestGetEnvVal_EmptySliceKeptWithoutDefaultOnEmpty(t *testing.T) {
+	cc := configConstraint{EnvName: strKey, SliceType: true, Separator: comma, HasDefault: true, Default: strSliceDefaultVal, Source: MapSource{strKey: empty}}
+	envVar, err := str[string](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, []string(nil), envVar)
+
+	cfg := struct {
+		Brokers []string `almi:"env=BROKERS,type=[,]string,default=[a,b]"`
+	}{}
+	err = Load(context.Background(), &cfg, MapSource{brokersEnv: empty})
+	assert.Nil(t, err)
+	assert.Empty(t, cfg.Brokers)
+}
+
+func TestGetEnvVal_SliceDefaultUsedWhenEmptyWithDefaultOnEmpty(t *testing.T) {
+	cc := configConstraint{EnvName: strKey, SliceType: true, Separator: comma, HasDefault: true, Default: strSliceDefaultVal, DefaultOnEmpty: true, Source: MapSource{strKey: empty}}
+	envVar, err := str[string](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, strSlice, envVar)
+}
+
+func TestAlmiAton_SuccessfullyConvertFloats(t *testing.T) {
+	cc := configConstraint{EnvName: floatKey, Source: MapSource{floatKey: floatVal}}
+	envVar, err := aton[float64](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, floatV64, envVar)
+
+	envVar, err = aton[float32](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, floatV32, envVar)
+}
+
+func TestAlmiAton_SuccessfullyConvertFloatSlice(t *testing.T) {
+	cc := configConstraint{EnvName: floatKey, SliceType: true, Separator: comma, Source: MapSource{floatKey: floatSliceVal}}
+	envVar, err := aton[float64](cc)
+	assert.Nil(t, err)
+	assert.Equal(t, floatSlice, envVar)
+}
+
+func TestAlmiAton_SuccessfullyConvertIntSyntax(t *testing.T) {
+	for val, expect := range intSyntaxVals {
+		cc := configConstraint{EnvName: intKey, Source: MapSource{intKey: val}}
+		envVar, err := aton[int](cc)
+		assert.Nil(t, err, val)
+		assert.Equal(t, expect, envVar, val)
+	}
+}
+
+func TestAlmiAton_FailOutOfRange(t *testing.T) {
+	cc := configConstraint{FieldName: intKey, EnvName: intKey, Type: _int8, Source: MapSource{intKey: outOfRangeVal}}
+	envVar, err := aton[int8](cc)
+	assert.Equal(t, int8(0), envVar)
+
+	var rangeErr *almierrors.RangeError
+	assert.ErrorAs(t, err, &rangeErr)
+	assert.Equal(t, &almierrors.RangeError{Field: intKey, Value: outOfRangeVal, Type: _int8}, rangeErr)
+	assert.ErrorIs(t, err, strconv.ErrRange)
+
+	envVar, err = aton[uint8](configConstraint{EnvName: intKey, Source: MapSource{intKey: outOfRangeVal}})
+	assert.Equal(t, uint8(0), envVar)
+	assert.ErrorAs(t, err, &rangeErr)
+	assert.Equal(t, _uint8, rangeErr.Type)
+}
+
+func TestAlmiAton_FailNegativeUnsigned(t *testing.T) {
+	cc := configConstraint{EnvName: intKey, Source: MapSource{intKey: negativeVal}}
+	envVar, err := aton[uint](cc)
+	assert.Equal(t, uint(0), envVar)
+	assert.NotNil(t, err)
+}
+
+func TestAlmiAtoRB_FailOutOfRange(t *testing.T) {
+	cc := configConstraint{EnvName: runeKey, Type: _byte, Source: MapSource{runeKey: outOfRangeVal}}
+	envVar, err := atoRB[byte](cc)
+	assert.Equal(t, byte(0), envVar)
+
+	var rangeErr *almierrors.RangeError
+	assert.ErrorAs(t, err, &rangeErr)
+}
+
+func TestAlmiDuration_SuccessfullyConvertDuration(t *testing.T) {
+	cc := configConstraint{EnvName: durationKey, Source: MapSource{durationKey: durationVal}}
+	envVar, err := atoDuration(cc)
+	assert.Nil(t, err)
+	assert.Equal(t, durationV, envVar)
+}
+
+func TestAlmiDuration_FailConvertDuration(t *testing.T) {
+	cc := configConstraint{EnvName: durationKey, Source: MapSource{durationKey: badDurationVal}}
+	envVar, err := atoDuration(cc)
+	assert.Equal(t, time.Duration(0), envVar)
+	assert.NotNil(t, err)
+}
+
+func TestAlmiDuration_SuccessfullyGetSliceDefaultValue(t *testing.T) {
+	cc := configConstraint{EnvName: durationKey, SliceType: true, Separator: comma, HasDefault: true, Default: durationSliceDefVal, Source: MapSource{}}
+	envVar, err := atoDuration(cc)
+	assert.Nil(t, err)
+	assert.Equal(t, durationSlice, envVar)
+}
+
+func TestAlmiTime_SuccessfullyConvertTimeWithDefaultLayout(t *testing.T) {
+	cc := configConstraint{EnvName: timeKey, Source: MapSource{timeKey: timeVal}}
+	envVar, err := atoTime(cc)
+	assert.Nil(t, err)
+	assert.Equal(t, timeV, envVar)
+}
+
+func TestAlmiTime_SuccessfullyConvertTimeWithLayout(t *testing.T) {
+	for _, layout := range []string{dateOnly, dateLayout} {
+		cc := configConstraint{EnvName: timeKey, Layout: layout, Source: MapSource{timeKey: dateVal}}
+		envVar, err := atoTime(cc)
+		assert.Nil(t, err)
+		assert.Equal(t, dateV, envVar)
+	}
+}
+
+func TestAlmiTime_FailConvertTime(t *testing.T) {
+	cc := configConstraint{EnvName: timeKey, Source: MapSource{timeKey: dateVal}}
+	envVar, err := atoTime(cc)
+	assert.Equal(t, time.Time{}, envVar)
+	assert.NotNil(t, err)
+}
+
+func TestAlmiTime_SuccessfullyConvertTimeSlice(t *testing.T) {
+	cc := configConstraint{EnvName: timeKey, SliceType: true, Separator: semicolon, Source: MapSource{timeKey: timeSliceVal}}
+	envVar, err := atoTime(cc)
+	assert.Nil(t, err)
+	assert.Equal(t, timeSlice, envVar)
+}
+
+func TestAlmiMap_SuccessfullyConvertMap(t *testing.T) {
+	cc := configConstraint{EnvName: mapKey, MapType: true, Separator: comma, KVSeparator: colon, KeyType: _string, Type: _int, Source: MapSource{mapKey: mapVal}}
+	envVar, err := atoMap(cc)
+	assert.Nil(t, err)
+	assert.Equal(t, stringIntMap, envVar)
+}
+
+func TestAlmiMap_SuccessfullyConvertMapOfOtherTypes(t *testing.T) {
+	cc := configConstraint{EnvName: mapKey, MapType: true, Separator: semicolon, KVSeparator: colon, KeyType: _uint8, Type: _duration, Source: MapSource{mapKey: durationMapVal}}
+	envVar, err := atoMap(cc)
+	assert.Nil(t, err)
+	assert.Equal(t, intDurationMap, envVar)
+}
+
+func TestAlmiMap_SuccessfullyGetDefaultValue(t *testing.T) {
+	cc := configConstraint{EnvName: mapKey, MapType: true, Separator: comma, KVSeparator: colon, KeyType: _string, Type: _int, HasDefault: true, Default: mapDefaultVal, Source: MapSource{}}
+	envVar, err := atoMap(cc)
+	assert.Nil(t, err)
+	assert.Equal(t, stringIntMap, envVar)
+}
+
+func TestAlmiMap_FailConvertMap(t *testing.T) {
+	failures := map[string]error{
+		malformedMapVal: almierrors.MalformedMapPairErr,
+		duplicateMapVal: almierrors.DuplicateMapKeyErr,
+		badMapElemVal:   strconv.ErrSyntax,
+	}
+
+	for val, expect := range failures {
+		cc := configConstraint{EnvName: mapKey, MapType: true, Separator: comma, KVSeparator: colon, KeyType: _string, Type: _int, Source: MapSource{mapKey: val}}
+		envVar, err := atoMap(cc)
+		assert.Nil(t, envVar, val)
+		assert.ErrorIs(t, err, expect, val)
+	}
+}
+
+func TestAlmiMap_FailGetDefaultValue(t *testing.T) {
+	cc := configConstraint{EnvName: mapKey, MapType: true, Separator: comma, KVSeparator: colon, KeyType: _string, Type: _int, HasDefault: true, Default: badMapDefaultVal, Source: MapSource{}}
+	envVar, err := atoMap(cc)
+	assert.Nil(t, envVar)
+	assert.ErrorIs(t, err, almierrors.MapDefaultValueFormatErr)
+}